@@ -6,9 +6,12 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
+	"net"
 	"os"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
@@ -62,6 +65,21 @@ func newListenerRequest(t *testing.T,
 	return httpRequest
 }
 
+func newListenerBatchRequest(t *testing.T, url string, body any, encodedCertificate string) *http.Request {
+	t.Helper()
+
+	jsonBody, err := json.Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpRequest, err := http.NewRequestWithContext(t.Context(), http.MethodPost, url, bytes.NewBuffer(jsonBody))
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpRequest.Header.Set(certificate.XFCCHeader, certificate.CertificateKey+encodedCertificate)
+	return httpRequest
+}
+
 type GenericTestEvt struct {
 	evt types.GenericEvent
 	mu  sync.Mutex
@@ -111,6 +129,100 @@ func TestHandler(t *testing.T) {
 	}
 }
 
+func TestBatchHandler(t *testing.T) {
+	t.Parallel()
+	// SETUP
+	log := setupLogger()
+	skrEventsListener := newTestListener(":8082", "kyma", log)
+
+	handlerUnderTest := skrEventsListener.HandleSKREventBatch()
+	responseRecorder := httptest.NewRecorder()
+
+	// GIVEN
+	testWatcherEvts := []*types.WatchEvent{
+		{
+			Watched:    types.ObjectKey{Name: "first", Namespace: v1.NamespaceDefault},
+			WatchedGvk: v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
+		},
+		{
+			Watched:    types.ObjectKey{Name: "", Namespace: v1.NamespaceDefault},
+			WatchedGvk: v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
+		},
+		{
+			Watched:    types.ObjectKey{Name: "second", Namespace: v1.NamespaceDefault},
+			WatchedGvk: v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
+		},
+	}
+	pemCert, err := utils.NewPemCertificateBuilder().Build()
+	require.NoError(t, err)
+	httpRequest := newListenerBatchRequest(t, "http://localhost:8082/v3/kyma/event", testWatcherEvts, pemCert)
+	receivedNames := make(chan string, len(testWatcherEvts))
+	go func() {
+		for range 2 {
+			evt := <-skrEventsListener.ReceivedEvents()
+			watched, _ := evt.Object.Object["watched"].(types.ObjectKey)
+			receivedNames <- watched.Name
+		}
+	}()
+
+	// WHEN
+	handlerUnderTest(responseRecorder, httpRequest)
+
+	// THEN
+	resp := responseRecorder.Result()
+	assert.Equal(t, http.StatusOK, resp.StatusCode)
+	batchResponse := types.BatchResponse{}
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&batchResponse))
+	assert.Equal(t, 2, batchResponse.Accepted)
+	require.Len(t, batchResponse.Errors, 1)
+	assert.Equal(t, 1, batchResponse.Errors[0].Index)
+	assert.Equal(t, "first", <-receivedNames)
+	assert.Equal(t, "second", <-receivedNames)
+}
+
+func TestListener_AcceptsBatchesLargerThanSingleEventLimit(t *testing.T) {
+	t.Parallel()
+	probe, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := probe.Addr().String()
+	require.NoError(t, probe.Close())
+	skrEventsListener := newTestListener(addr, "kyma", setupLogger())
+	go func() { _ = skrEventsListener.Start(t.Context()) }()
+	go func() {
+		for event := range skrEventsListener.ReceivedEvents() {
+			_ = event
+		}
+	}()
+
+	// GIVEN 20 events with a patch of 4096 bytes each
+	patch, err := json.Marshal([]map[string]string{{"op": "replace", "path": "/spec/channel",
+		"value": strings.Repeat("x", 4050)}})
+	require.NoError(t, err)
+	testWatcherEvts := make([]*types.WatchEvent, 20)
+	for idx := range testWatcherEvts {
+		testWatcherEvts[idx] = &types.WatchEvent{
+			Watched:    types.ObjectKey{Name: "kyma", Namespace: v1.NamespaceDefault},
+			WatchedGvk: v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
+			Patch:      patch,
+		}
+	}
+	pemCert, err := utils.NewPemCertificateBuilder().Build()
+	require.NoError(t, err)
+
+	for path, statusCode := range map[string]int{"/v3/kyma/event": http.StatusOK,
+		"/v2/kyma/event": http.StatusRequestEntityTooLarge} {
+		request := newListenerBatchRequest(t, "http://"+addr+path, testWatcherEvts, pemCert)
+		require.Greater(t, request.ContentLength, int64(16384))
+		var resp *http.Response
+		require.Eventually(t, func() bool {
+			resp, err = http.DefaultClient.Do(request.Clone(t.Context()))
+			return err == nil
+		}, time.Second, 10*time.Millisecond)
+		require.NoError(t, resp.Body.Close())
+		assert.Equal(t, statusCode, resp.StatusCode, path)
+	}
+}
+
 func TestMiddleware(t *testing.T) {
 	t.Parallel()
 	// SETUP
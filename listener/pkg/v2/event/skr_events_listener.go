@@ -2,10 +2,12 @@ package event
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -16,7 +18,10 @@ import (
 
 const (
 	paramContractVersion    = "2"
+	batchContractVersion    = "3"
 	requestSizeLimitInBytes = 16384 // 16KB
+	// batchRequestSizeLimitInBytes allows a batch of 64 WatchEvents of the size limit of a single one.
+	batchRequestSizeLimitInBytes = 64 * requestSizeLimitInBytes // 1MB
 )
 
 var errRequestSizeExceeded = errors.New("requestSizeExceeded")
@@ -31,8 +36,9 @@ type SKREventListener struct {
 
 // NewSKREventListener creates a new instance of SKREventListener.
 // addr specifies the TCP address for the server to listen on in the form "host:port".
-// componentName is used to construct the API path for receiving events.
-// The API path will be in the format "/v2/{componentName}/event".
+// componentName is used to construct the API paths for receiving events.
+// Single events are received on "/v2/{componentName}/event",
+// batches of events as JSON array on "/v3/{componentName}/event".
 func NewSKREventListener(addr, componentName string,
 ) *SKREventListener {
 	unbufferedEventsChan := make(chan types.GenericEvent)
@@ -49,6 +55,14 @@ func NewSKREventListener(addr, componentName string,
 //   - watched: NamespacedName
 //   - watched-gvk: GroupVersionKind
 //   - runtime-id: string
+//   - operation: string, one of CREATE, UPDATE or DELETE, empty if the watcher did not send it
+//   - uid: string, empty if the watcher did not send it
+//   - resource-version: string, empty if the watcher did not send it
+//   - generation: int64, 0 if the watcher did not send it
+//   - changed-paths: []string, JSON pointers of the fields changed by an UPDATE, nil if not sent
+//   - patch: string, JSON patch of the fields changed by an UPDATE, empty if not sent
+//   - deleting: bool, true for an UPDATE of an object whose deletion has started
+//   - dry-run: bool, true for an event of a dry-run admission request
 func (l *SKREventListener) ReceivedEvents() <-chan types.GenericEvent {
 	return l.events
 }
@@ -62,11 +76,14 @@ func (l *SKREventListener) Start(ctx context.Context) error {
 
 	listenerPattern := fmt.Sprintf("/v%s/%s/event", paramContractVersion, l.ComponentName)
 	router.HandleFunc(listenerPattern, l.RequestSizeLimitingMiddleware(l.HandleSKREvent()))
+	batchListenerPattern := fmt.Sprintf("/v%s/%s/event", batchContractVersion, l.ComponentName)
+	router.HandleFunc(batchListenerPattern,
+		l.requestSizeLimitingMiddleware(batchRequestSizeLimitInBytes, l.HandleSKREventBatch()))
 
 	// start web server
 	const defaultTimeout = time.Second * 60
 	server := &http.Server{
-		Addr: l.Addr, Handler: http.MaxBytesHandler(router, requestSizeLimitInBytes),
+		Addr: l.Addr, Handler: http.MaxBytesHandler(router, batchRequestSizeLimitInBytes),
 		ReadHeaderTimeout: defaultTimeout, ReadTimeout: defaultTimeout,
 		WriteTimeout: defaultTimeout,
 	}
@@ -74,6 +91,7 @@ func (l *SKREventListener) Start(ctx context.Context) error {
 		l.Logger.WithValues(
 			"Addr", l.Addr,
 			"ApiPath", listenerPattern,
+			"BatchApiPath", batchListenerPattern,
 		).Info("Listener is starting up...")
 		err := server.ListenAndServe()
 		if err != nil && !errors.Is(err, http.ErrServerClosed) {
@@ -90,15 +108,21 @@ func (l *SKREventListener) Start(ctx context.Context) error {
 }
 
 func (l *SKREventListener) RequestSizeLimitingMiddleware(next http.HandlerFunc) http.HandlerFunc {
+	return l.requestSizeLimitingMiddleware(requestSizeLimitInBytes, next)
+}
+
+// requestSizeLimitingMiddleware answers requests with a body larger than limit with 413 Request Entity Too Large.
+func (l *SKREventListener) requestSizeLimitingMiddleware(limit int64, next http.HandlerFunc) http.HandlerFunc {
 	return func(writer http.ResponseWriter, request *http.Request) {
-		if request.ContentLength > requestSizeLimitInBytes {
+		if request.ContentLength > limit {
 			metrics.RecordHTTPRequestExceedingSizeLimit()
-			errorMessage := fmt.Sprintf("Body size greater than %d bytes is not allowed", requestSizeLimitInBytes)
+			errorMessage := fmt.Sprintf("Body size greater than %d bytes is not allowed", limit)
 			l.Logger.Error(errRequestSizeExceeded, errorMessage)
 			http.Error(writer, errorMessage, http.StatusRequestEntityTooLarge)
 			return
 		}
 
+		request.Body = http.MaxBytesReader(writer, request.Body, limit)
 		executeRequestAndUpdateMetrics(next, writer, request)
 	}
 }
@@ -126,8 +150,59 @@ func (l *SKREventListener) HandleSKREvent() http.HandlerFunc {
 		genericEvtObject := GenericEvent(watcherEvent)
 		// add event to the channel
 		l.events <- types.GenericEvent{Object: genericEvtObject}
-		l.Logger.Info("dispatched event object into channel", "resource-name", genericEvtObject.GetName())
+		l.Logger.Info("dispatched event object into channel", "resource-name", genericEvtObject.GetName(),
+			"event-id", req.Header.Get(types.EventIDHeader))
+		writer.WriteHeader(http.StatusOK)
+	}
+}
+
+// HandleSKREventBatch dispatches each valid WatchEvent of a batch into the channel.
+// The response body is a types.BatchResponse listing the rejected WatchEvents.
+func (l *SKREventListener) HandleSKREventBatch() http.HandlerFunc {
+	return func(writer http.ResponseWriter, req *http.Request) {
+		// http method support: POST only is allowed
+		if req.Method != http.MethodPost {
+			errorMessage := req.Method + " method is not allowed on this path"
+			l.Logger.Error(nil, errorMessage)
+			http.Error(writer, errorMessage, http.StatusMethodNotAllowed)
+			return
+		}
+
+		l.Logger.V(1).Info("received event batch from SKR")
+
+		watcherEvents, itemErrors, unmarshalErr := UnmarshalSKREventBatch(req)
+		if unmarshalErr != nil {
+			l.Logger.Error(nil, unmarshalErr.Message)
+			http.Error(writer, unmarshalErr.Message, unmarshalErr.HTTPErrorCode)
+			return
+		}
+
+		eventIDs := strings.Split(req.Header.Get(types.EventIDHeader), ",")
+		for _, itemError := range itemErrors {
+			var eventID string
+			if itemError.Index >= 0 && itemError.Index < len(eventIDs) {
+				eventID = eventIDs[itemError.Index]
+			}
+			l.Logger.Error(nil, "rejected event of batch", "index", itemError.Index, "reason", itemError.Message,
+				"event-id", eventID)
+		}
+		for _, watcherEvent := range watcherEvents {
+			genericEvtObject := GenericEvent(watcherEvent)
+			l.events <- types.GenericEvent{Object: genericEvtObject}
+			l.Logger.Info("dispatched event object into channel", "resource-name", genericEvtObject.GetName())
+		}
+		l.Logger.Info("dispatched event batch into channel", "accepted", len(watcherEvents),
+			"event-ids", req.Header.Get(types.EventIDHeader))
+
+		writer.Header().Set("Content-Type", "application/json")
 		writer.WriteHeader(http.StatusOK)
+		err := json.NewEncoder(writer).Encode(types.BatchResponse{
+			Accepted: len(watcherEvents),
+			Errors:   itemErrors,
+		})
+		if err != nil {
+			l.Logger.Error(err, "failed to write batch response")
+		}
 	}
 }
 
@@ -15,7 +15,7 @@ import (
 )
 
 const (
-	contentMapCapacity = 4
+	contentMapCapacity = 12
 )
 
 type UnmarshalError struct {
@@ -24,6 +24,87 @@ type UnmarshalError struct {
 }
 
 func UnmarshalSKREvent(req *http.Request) (*types.WatchEvent, *UnmarshalError) {
+	body, unmarshalError := readVersionedBody(req)
+	if unmarshalError != nil {
+		return nil, unmarshalError
+	}
+
+	watcherEvent := &types.WatchEvent{}
+	err := json.Unmarshal(body, watcherEvent)
+	if err != nil {
+		return nil, &UnmarshalError{
+			fmt.Sprintf("could not unmarshal watcher event: Body{%s}",
+				string(body)), http.StatusInternalServerError,
+		}
+	}
+
+	skrMetaFromRequest, unmarshalError := getSkrMetaFromRequest(req)
+	if unmarshalError != nil {
+		return nil, unmarshalError
+	}
+	watcherEvent.SkrMeta = skrMetaFromRequest
+
+	return watcherEvent, nil
+}
+
+// UnmarshalSKREventBatch parses a JSON array of WatchEvents.
+// Items that cannot be decoded or are incomplete are skipped and reported as BatchItemError,
+// while an UnmarshalError is only returned if the request as a whole cannot be processed.
+func UnmarshalSKREventBatch(req *http.Request) ([]*types.WatchEvent, []types.BatchItemError, *UnmarshalError) {
+	body, unmarshalError := readVersionedBody(req)
+	if unmarshalError != nil {
+		return nil, nil, unmarshalError
+	}
+
+	var rawEvents []json.RawMessage
+	err := json.Unmarshal(body, &rawEvents)
+	if err != nil {
+		return nil, nil, &UnmarshalError{
+			fmt.Sprintf("could not unmarshal watcher event batch: Body{%s}",
+				string(body)), http.StatusBadRequest,
+		}
+	}
+
+	skrMetaFromRequest, unmarshalError := getSkrMetaFromRequest(req)
+	if unmarshalError != nil {
+		return nil, nil, unmarshalError
+	}
+
+	watcherEvents := make([]*types.WatchEvent, 0, len(rawEvents))
+	itemErrors := make([]types.BatchItemError, 0)
+	for idx, rawEvent := range rawEvents {
+		watcherEvent := &types.WatchEvent{}
+		err = json.Unmarshal(rawEvent, watcherEvent)
+		if err == nil {
+			err = validateWatchEvent(watcherEvent)
+		}
+		if err != nil {
+			itemErrors = append(itemErrors, types.BatchItemError{Index: idx, Message: err.Error()})
+			continue
+		}
+		watcherEvent.SkrMeta = skrMetaFromRequest
+		watcherEvents = append(watcherEvents, watcherEvent)
+	}
+
+	return watcherEvents, itemErrors, nil
+}
+
+var (
+	errEmptyWatchedName = errors.New("watched name must not be empty")
+	errEmptyWatchedKind = errors.New("watched kind must not be empty")
+)
+
+func validateWatchEvent(watcherEvent *types.WatchEvent) error {
+	if watcherEvent.Watched.Name == "" {
+		return errEmptyWatchedName
+	}
+	if watcherEvent.WatchedGvk.Kind == "" {
+		return errEmptyWatchedKind
+	}
+	return nil
+}
+
+func readVersionedBody(req *http.Request) ([]byte, *UnmarshalError) {
 	pathVariables := strings.Split(req.URL.Path, "/")
 
 	var contractVersion string
@@ -38,26 +119,16 @@ func UnmarshalSKREvent(req *http.Request) (*types.WatchEvent, *UnmarshalError) {
 	}
 
 	body, err := io.ReadAll(req.Body)
-	if err != nil {
-		return nil, &UnmarshalError{"could not read request body", http.StatusInternalServerError}
-	}
-
-	watcherEvent := &types.WatchEvent{}
-	err = json.Unmarshal(body, watcherEvent)
-	if err != nil {
+	if maxBytesErr := (&http.MaxBytesError{}); errors.As(err, &maxBytesErr) {
 		return nil, &UnmarshalError{
-			fmt.Sprintf("could not unmarshal watcher event: Body{%s}",
-				string(body)), http.StatusInternalServerError,
+			fmt.Sprintf("Body size greater than %d bytes is not allowed", maxBytesErr.Limit),
+			http.StatusRequestEntityTooLarge,
 		}
 	}
-
-	skrMetaFromRequest, unmarshalError := getSkrMetaFromRequest(req)
-	if unmarshalError != nil {
-		return nil, unmarshalError
+	if err != nil {
+		return nil, &UnmarshalError{"could not read request body", http.StatusInternalServerError}
 	}
-	watcherEvent.SkrMeta = skrMetaFromRequest
-
-	return watcherEvent, nil
+	return body, nil
 }
 
 func getSkrMetaFromRequest(req *http.Request) (types.SkrMeta, *UnmarshalError) {
@@ -94,5 +165,13 @@ func UnstructuredContent(watcherEvt *types.WatchEvent) map[string]any {
 	content["watched"] = watcherEvt.Watched
 	content["watched-gvk"] = watcherEvt.WatchedGvk
 	content["runtime-id"] = watcherEvt.SkrMeta.RuntimeId
+	content["operation"] = string(watcherEvt.Operation)
+	content["uid"] = string(watcherEvt.UID)
+	content["resource-version"] = watcherEvt.ResourceVersion
+	content["generation"] = watcherEvt.Generation
+	content["changed-paths"] = watcherEvt.ChangedPaths
+	content["patch"] = string(watcherEvt.Patch)
+	content["deleting"] = watcherEvt.Deleting
+	content["dry-run"] = watcherEvt.DryRun
 	return content
 }
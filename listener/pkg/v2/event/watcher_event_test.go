@@ -26,9 +26,13 @@ type unmarshalTestCase struct {
 func TestUnmarshalSKREvent(t *testing.T) {
 	t.Parallel()
 	testWatcherEvt := &types.WatchEvent{
-		Watched:    types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
-		WatchedGvk: v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
-		SkrMeta:    types.SkrMeta{RuntimeId: "test-cert"},
+		Watched:         types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		WatchedGvk:      v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
+		Operation:       types.OperationUpdate,
+		UID:             "5b1c8f4e-6a1d-4c2b-9f1e-3d2a7c9b8e01",
+		ResourceVersion: "4711",
+		Generation:      3,
+		SkrMeta:         types.SkrMeta{RuntimeId: "test-cert"},
 	}
 
 	testCases := []unmarshalTestCase{
@@ -92,3 +96,138 @@ func TestUnmarshalSKREvent_WhenNoCommonNameInClientCertificate_ReturnsError(t *t
 	require.Equal(t, "client certificate common name is empty", unmarshalErr.Message)
 	require.Equal(t, http.StatusBadRequest, unmarshalErr.HTTPErrorCode)
 }
+
+func TestUnmarshalSKREvent_WhenOperationIsMissing_DecodesEvent(t *testing.T) {
+	t.Parallel()
+	// GIVEN
+	legacyEvent := map[string]any{
+		"watched":    map[string]string{"name": "watched-resource", "namespace": v1.NamespaceDefault},
+		"watchedGvk": map[string]string{"kind": "kyma", "group": "operator.kyma-project.io", "version": "v1alpha1"},
+	}
+	pemCert, err := utils.NewPemCertificateBuilder().Build()
+	require.NoError(t, err)
+	req := newListenerBatchRequest(t, hostname+"/v2/kyma/event", legacyEvent, pemCert)
+	// WHEN
+	watcherEvent, unmarshalErr := listenerEvent.UnmarshalSKREvent(req)
+	// THEN
+	require.Nil(t, unmarshalErr)
+	require.Empty(t, watcherEvent.Operation)
+	require.Equal(t, "", listenerEvent.UnstructuredContent(watcherEvent)["operation"])
+}
+
+func TestUnstructuredContent_ContainsOperation(t *testing.T) {
+	t.Parallel()
+	watcherEvent := &types.WatchEvent{
+		Watched:   types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		Operation: types.OperationDelete,
+	}
+
+	content := listenerEvent.UnstructuredContent(watcherEvent)
+
+	require.Equal(t, "DELETE", content["operation"])
+}
+
+func TestUnstructuredContent_ContainsObjectIdentity(t *testing.T) {
+	t.Parallel()
+	watcherEvent := &types.WatchEvent{
+		Watched:         types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		UID:             "5b1c8f4e-6a1d-4c2b-9f1e-3d2a7c9b8e01",
+		ResourceVersion: "4711",
+		Generation:      3,
+	}
+
+	content := listenerEvent.UnstructuredContent(watcherEvent)
+
+	require.Equal(t, "5b1c8f4e-6a1d-4c2b-9f1e-3d2a7c9b8e01", content["uid"])
+	require.Equal(t, "4711", content["resource-version"])
+	require.Equal(t, int64(3), content["generation"])
+}
+
+func TestUnstructuredContent_ContainsChangeSummary(t *testing.T) {
+	t.Parallel()
+	watcherEvent := &types.WatchEvent{
+		Watched:      types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		Operation:    types.OperationUpdate,
+		ChangedPaths: []string{"/spec/channel"},
+		Patch:        []byte(`[{"op":"replace","path":"/spec/channel","value":"fast"}]`),
+	}
+
+	content := listenerEvent.UnstructuredContent(watcherEvent)
+
+	require.Equal(t, []string{"/spec/channel"}, content["changed-paths"])
+	require.JSONEq(t, `[{"op":"replace","path":"/spec/channel","value":"fast"}]`, content["patch"].(string))
+}
+
+func TestUnstructuredContent_ContainsDeletingMarker(t *testing.T) {
+	t.Parallel()
+	watcherEvent := &types.WatchEvent{
+		Watched:   types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		Operation: types.OperationUpdate,
+		Deleting:  true,
+	}
+
+	content := listenerEvent.UnstructuredContent(watcherEvent)
+
+	deleting, isBool := content["deleting"].(bool)
+	require.True(t, isBool)
+	require.True(t, deleting)
+}
+
+func TestUnstructuredContent_ContainsDryRunMarker(t *testing.T) {
+	t.Parallel()
+	watcherEvent := &types.WatchEvent{
+		Watched:   types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		Operation: types.OperationCreate,
+		DryRun:    true,
+	}
+
+	content := listenerEvent.UnstructuredContent(watcherEvent)
+
+	dryRun, isBool := content["dry-run"].(bool)
+	require.True(t, isBool)
+	require.True(t, dryRun)
+}
+
+func TestUnmarshalSKREventBatch_ReportsInvalidItems(t *testing.T) {
+	t.Parallel()
+	// GIVEN
+	validEvent := map[string]any{
+		"watched":    map[string]string{"name": "watched-resource", "namespace": v1.NamespaceDefault},
+		"watchedGvk": map[string]string{"kind": "kyma", "group": "operator.kyma-project.io", "version": "v1alpha1"},
+	}
+	missingName := map[string]any{
+		"watched":    map[string]string{"namespace": v1.NamespaceDefault},
+		"watchedGvk": map[string]string{"kind": "kyma", "group": "operator.kyma-project.io", "version": "v1alpha1"},
+	}
+	pemCert, err := utils.NewPemCertificateBuilder().Build()
+	require.NoError(t, err)
+	req := newListenerBatchRequest(t, hostname+"/v3/kyma/event",
+		[]any{validEvent, missingName, "not-an-event"}, pemCert)
+	// WHEN
+	watcherEvents, itemErrors, unmarshalErr := listenerEvent.UnmarshalSKREventBatch(req)
+	// THEN
+	require.Nil(t, unmarshalErr)
+	require.Len(t, watcherEvents, 1)
+	require.Equal(t, &types.WatchEvent{
+		Watched:    types.ObjectKey{Name: "watched-resource", Namespace: v1.NamespaceDefault},
+		WatchedGvk: v1.GroupVersionKind{Kind: "kyma", Group: "operator.kyma-project.io", Version: "v1alpha1"},
+		SkrMeta:    types.SkrMeta{RuntimeId: "test-cert"},
+	}, watcherEvents[0])
+	require.Len(t, itemErrors, 2)
+	require.Equal(t, 1, itemErrors[0].Index)
+	require.Equal(t, "watched name must not be empty", itemErrors[0].Message)
+	require.Equal(t, 2, itemErrors[1].Index)
+}
+
+func TestUnmarshalSKREventBatch_WhenBodyIsNoArray_ReturnsError(t *testing.T) {
+	t.Parallel()
+	// GIVEN
+	pemCert, err := utils.NewPemCertificateBuilder().Build()
+	require.NoError(t, err)
+	req := newListenerBatchRequest(t, hostname+"/v3/kyma/event", map[string]string{}, pemCert)
+	// WHEN
+	_, _, unmarshalErr := listenerEvent.UnmarshalSKREventBatch(req)
+	// THEN
+	require.NotNil(t, unmarshalErr)
+	require.Equal(t, http.StatusBadRequest, unmarshalErr.HTTPErrorCode)
+}
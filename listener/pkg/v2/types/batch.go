@@ -0,0 +1,16 @@
+package types
+
+// BatchResponse is returned by the listener for a batch of WatchEvents.
+type BatchResponse struct {
+	// Accepted is the number of WatchEvents dispatched to the listener's consumers.
+	Accepted int `json:"accepted"`
+	// Errors lists the WatchEvents of the batch that were rejected.
+	Errors []BatchItemError `json:"errors,omitempty"`
+}
+
+// BatchItemError describes why a single WatchEvent of a batch was rejected.
+type BatchItemError struct {
+	// Index is the position of the rejected WatchEvent in the batch.
+	Index   int    `json:"index"`
+	Message string `json:"message"`
+}
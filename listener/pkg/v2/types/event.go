@@ -1,6 +1,8 @@
 package types
 
 import (
+	"encoding/json"
+
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
 )
@@ -8,8 +10,40 @@ import (
 // ObjectKey identifies a Kubernetes Object.
 type ObjectKey = types.NamespacedName
 
+// Operation is the admission operation that triggered a WatchEvent.
+type Operation string
+
+const (
+	OperationCreate Operation = "CREATE"
+	OperationUpdate Operation = "UPDATE"
+	OperationDelete Operation = "DELETE"
+)
+
+// EventIDHeader carries the ID of the admission request a WatchEvent originates from. For a batch,
+// it lists the IDs of the WatchEvents in the order of the batch, separated by commas.
+const EventIDHeader = "X-Watcher-Event-Id"
+
 type WatchEvent struct {
 	Watched    ObjectKey               `json:"watched"`
 	WatchedGvk metav1.GroupVersionKind `json:"watchedGvk"`
-	SkrMeta    SkrMeta                 `json:"-"`
+	// Operation is empty for events of watchers that do not send it.
+	Operation Operation `json:"operation,omitempty"`
+	// UID, ResourceVersion and Generation identify the state of the watched object that triggered the event.
+	// They are empty for events of watchers that do not send them.
+	UID             types.UID `json:"uid,omitempty"`
+	ResourceVersion string    `json:"resourceVersion,omitempty"`
+	Generation      int64     `json:"generation,omitempty"`
+	// ChangedPaths lists the JSON pointers of the watched fields changed by an UPDATE, e.g. "/spec/channel".
+	// It is only sent if enabled for the module in the watcher.
+	ChangedPaths []string `json:"changedPaths,omitempty"`
+	// Patch is the JSON patch of the watched fields changed by an UPDATE.
+	// It is only sent if enabled for the module in the watcher and if it does not exceed the size limit.
+	Patch json.RawMessage `json:"patch,omitempty"`
+	// Deleting marks an UPDATE of an object whose deletion has started, i.e. its deletionTimestamp is set.
+	// Such an UPDATE is always sent when the deletionTimestamp is set or a finalizer is removed.
+	Deleting bool `json:"deleting,omitempty"`
+	// DryRun marks an event of a dry-run admission request, so nothing was persisted in SKR.
+	// Such events are only sent if enabled for the module in the watcher.
+	DryRun  bool    `json:"dryRun,omitempty"`
+	SkrMeta SkrMeta `json:"-"`
 }
@@ -2,6 +2,9 @@ module github.com/kyma-project/runtime-watcher/skr
 
 go 1.26.6
 
+// the watch event contract is defined by the listener of this repository
+replace github.com/kyma-project/runtime-watcher/listener => ../listener
+
 require (
 	github.com/go-logr/logr v1.4.4
 	github.com/go-logr/zapr v1.3.0
@@ -12,6 +15,7 @@ require (
 	go.uber.org/zap v1.28.0
 	k8s.io/api v0.36.3
 	k8s.io/apimachinery v0.36.3
+	sigs.k8s.io/yaml v1.6.0
 )
 
 require (
@@ -43,5 +47,4 @@ require (
 	k8s.io/klog/v2 v2.140.0 // indirect
 	k8s.io/utils v0.0.0-20260210185600-b8788abfbbc2 // indirect
 	sigs.k8s.io/json v0.0.0-20250730193827-2d320260d730 // indirect
-	sigs.k8s.io/yaml v1.6.0 // indirect
 )
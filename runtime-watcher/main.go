@@ -17,24 +17,30 @@ limitations under the License.
 package main
 
 import (
+	"context"
 	"crypto/tls"
 	"errors"
 	"flag"
 	"fmt"
 	"net/http"
 	"os"
+	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/go-logr/logr"
 	"github.com/go-logr/zapr"
-	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"go.uber.org/zap"
 
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
 
 	"github.com/kyma-project/runtime-watcher/skr/pkg/admissionreview"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/health"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/metricsserver"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/requestparser"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/servingcert"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
 )
 
@@ -47,7 +53,8 @@ func main() {
 	var printVersion bool
 	var development bool
 	flag.BoolVar(&printVersion, "version", false, "Prints the watcher version and exits")
-	flag.BoolVar(&development, "development", true, "Enable development mode")
+	flag.BoolVar(&development, "development", true,
+		"Log for local development: debug level, console format and no sampling, unless set in the environment")
 	flag.Parse()
 
 	if printVersion {
@@ -59,8 +66,13 @@ func main() {
 		os.Exit(0)
 	}
 
+	logConfig, err := serverconfig.ParseLogConfigFromEnv(development)
+	if err != nil {
+		_, _ = fmt.Fprintf(os.Stderr, "Invalid log config: %v\n", err)
+		os.Exit(1)
+	}
 	// Initialize the global logger
-	zapLogger := setupLogger(development)
+	zapLogger := setupLogger(logConfig)
 	defer func() {
 		syncErr := zapLogger.Sync()
 		// Ignore EINVAL errors (Sync() is not supported for some file descriptors, it doesn't mean that logs are lost)
@@ -74,7 +86,8 @@ func main() {
 	}()
 	logger := zapr.NewLogger(zapLogger.With(zap.String("component", "skr-webhook")))
 
-	logger.Info("Starting Runtime Watcher", "Version", buildVersion)
+	logger.Info("Starting Runtime Watcher", "Version", buildVersion,
+		"logLevel", logConfig.Level.String(), "logFormat", logConfig.Format, "logSampling", logConfig.Sampling)
 
 	serverConfig, err := serverconfig.ParseFromEnv(logger)
 	if err != nil {
@@ -83,48 +96,162 @@ func main() {
 	}
 	logger.Info("Server config successfully parsed: " + serverConfig.PrettyPrint())
 
-	decoder := serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
-	requestParser := requestparser.NewRequestParser(decoder)
 	metrics := watchermetrics.NewMetrics()
 	metrics.RegisterAll()
 	metrics.UpdateFipsMode() // This won't change during runtime, so we can call it once at startup
 	logger.Info("All metrics registered")
 
-	http.Handle("/metrics", promhttp.Handler())
-	metricsServer := &http.Server{
-		Addr:              fmt.Sprintf(":%d", serverConfig.MetricsPort),
-		ReadHeaderTimeout: admissionreview.HTTPTimeout,
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGTERM, syscall.SIGINT)
+	defer stop()
+	err = run(ctx, logger, serverConfig, metrics)
+	if err != nil {
+		logger.Error(err, "error running skr-webhook server")
+		return
+	}
+}
+
+// run serves admission requests until ctx is done or the webhook server fails, and shuts down gracefully.
+func run(ctx context.Context, logger logr.Logger, serverConfig serverconfig.ServerConfig,
+	metrics *watchermetrics.WatcherMetrics,
+) error {
+	decoder := serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
+	requestParser := requestparser.NewRequestParser(decoder)
+
+	handler, err := admissionreview.NewHandler(logger, serverConfig, *requestParser, *metrics)
+	if err != nil {
+		return fmt.Errorf("failed to create admission handler: %w", err)
+	}
+	certLoader, err := servingcert.New(logger.WithName("serving-cert"), servingcert.Config{
+		Certificate:    watchermetrics.CertificateWebhookServing,
+		TLSCertPath:    serverConfig.TLSCertPath,
+		TLSKeyPath:     serverConfig.TLSKeyPath,
+		ReloadInterval: serverConfig.CertReloadInterval,
+	}, metrics)
+	if err != nil {
+		return fmt.Errorf("failed to load webhook serving certificate: %w", err)
+	}
+	handler.Start()
+	certReloadCtx, stopCertReload := context.WithCancel(context.Background())
+	defer stopCertReload()
+	go certLoader.Run(certReloadCtx)
+
+	probes := health.NewProbes(logger.WithName("probes"))
+	probes.AddReadinessCheck("serving-cert", certLoader.Check)
+	probes.AddReadinessCheck("kcp-client-cert", handler.CheckClientCertificates)
+	if serverConfig.ReadinessKCPWindow > 0 {
+		probes.AddReadinessCheck("kcp-reachable", func() error {
+			return handler.CheckKCPReachable(serverConfig.ReadinessKCPWindow)
+		})
+	}
+	webhookMux := http.NewServeMux()
+	webhookMux.HandleFunc("/validate/", handler.Handle)
+	webhookMux.HandleFunc("/healthz", probes.Healthz)
+	webhookMux.HandleFunc("/readyz", probes.Readyz)
+
+	metricsServer, err := newMetricsServer(logger, serverConfig, metrics)
+	if err != nil {
+		return err
 	}
 	go func() {
-		err = metricsServer.ListenAndServe()
+		err := metricsServer.ListenAndServe(certReloadCtx)
 		if err != nil {
 			logger.Error(err, "failed to serve metrics endpoint")
 		}
 	}()
-	logger.Info("Metrics server started")
 
-	handler := admissionreview.NewHandler(logger, serverConfig, *requestParser, *metrics)
-	http.HandleFunc("/validate/", handler.Handle)
-	server := http.Server{
+	server := &http.Server{
 		Addr:        fmt.Sprintf(":%d", serverConfig.Port),
+		Handler:     webhookMux,
 		ReadTimeout: admissionreview.HTTPTimeout,
 		TLSConfig: &tls.Config{
-			MinVersion: tls.VersionTLS13,
-			MaxVersion: tls.VersionTLS13,
+			MinVersion:     tls.VersionTLS13,
+			MaxVersion:     tls.VersionTLS13,
+			GetCertificate: certLoader.GetCertificate,
 		},
 	}
-	logger.Info("Starting server for validation endpoint", "Port", serverConfig.Port)
-	err = server.ListenAndServeTLS(serverConfig.TLSCertPath, serverConfig.TLSKeyPath)
+	serverErr := make(chan error, 1)
+	go func() {
+		logger.Info("Starting server for validation endpoint", "Port", serverConfig.Port)
+		// the certificate is provided by GetCertificate, so it can be rotated without a restart
+		serverErr <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case <-ctx.Done():
+		logger.Info("Received termination signal, shutting down",
+			"gracePeriod", serverConfig.ForwardingDrainTimeout)
+	case err = <-serverErr:
+		err = fmt.Errorf("failed to serve validation endpoint: %w", err)
+	}
+	shutdown(logger, metrics, handler, serverConfig.ForwardingDrainTimeout, server, metricsServer)
+	return err
+}
+
+func newMetricsServer(logger logr.Logger, serverConfig serverconfig.ServerConfig,
+	metrics *watchermetrics.WatcherMetrics,
+) (*metricsserver.Server, error) {
+	metricsServer, err := metricsserver.New(logger.WithName("metrics-server"), metricsserver.Config{
+		Port:            serverConfig.MetricsPort,
+		LocalhostOnly:   serverConfig.MetricsLocalhostOnly,
+		TLSCertPath:     serverConfig.MetricsTLSCertPath,
+		TLSKeyPath:      serverConfig.MetricsTLSKeyPath,
+		ClientCAPath:    serverConfig.MetricsClientCAPath,
+		BearerTokenPath: serverConfig.MetricsBearerTokenPath,
+		ReloadInterval:  serverConfig.CertReloadInterval,
+	}, metrics)
 	if err != nil {
-		logger.Error(err, "error starting skr-webhook server")
-		return
+		return nil, fmt.Errorf("failed to create metrics server: %w", err)
+	}
+	return metricsServer, nil
+}
+
+// metricsShutdownTimeout is the time the metrics server is given to complete in-flight scrapes on shutdown,
+// in addition to the grace period.
+const metricsShutdownTimeout = 5 * time.Second
+
+// shutdown stops accepting admission requests and waits for the in-flight ones and the pending deliveries
+// to KCP within the grace period. The metrics server is stopped last with a timeout of its own, so it serves
+// until the end, even if the grace period is used up.
+func shutdown(logger logr.Logger, metrics *watchermetrics.WatcherMetrics, handler *admissionreview.Handler,
+	gracePeriod time.Duration, server *http.Server, metricsServer *metricsserver.Server,
+) {
+	start := time.Now()
+	ctx, cancel := context.WithTimeout(context.Background(), gracePeriod)
+	defer cancel()
+
+	err := server.Shutdown(ctx)
+	if err != nil {
+		err = fmt.Errorf("failed to complete in-flight admission requests: %w", err)
+	}
+	err = errors.Join(err, handler.Shutdown(ctx))
+	if err != nil {
+		metrics.UpdateShutdownsTotal(watchermetrics.ShutdownFailed)
+		logger.Error(err, "Shutdown incomplete, pending events were not delivered to KCP",
+			"duration", time.Since(start))
+	} else {
+		metrics.UpdateShutdownsTotal(watchermetrics.ShutdownSucceeded)
+		logger.Info("Shutdown complete, pending events delivered to KCP", "duration", time.Since(start))
+	}
+
+	metricsCtx, cancelMetrics := context.WithTimeout(context.Background(), metricsShutdownTimeout)
+	defer cancelMetrics()
+	err = metricsServer.Shutdown(metricsCtx)
+	if err != nil {
+		logger.Error(err, "failed to shut down metrics server")
 	}
 }
 
-func setupLogger(development bool) *zap.Logger {
+func setupLogger(config serverconfig.LogConfig) *zap.Logger {
 	zapConfig := zap.NewProductionConfig()
-	if development {
-		zapConfig = zap.NewDevelopmentConfig()
+	zapConfig.Level = zap.NewAtomicLevelAt(config.Level)
+	zapConfig.Encoding = string(config.Format)
+	if config.Format == serverconfig.LogFormatConsole {
+		zapConfig.EncoderConfig = zap.NewDevelopmentEncoderConfig()
+	}
+	// the production preset logs the first 100 entries with the same level and message per second
+	// and every 100th after that
+	if !config.Sampling {
+		zapConfig.Sampling = nil
 	}
 
 	zapLogger, err := zapConfig.Build()
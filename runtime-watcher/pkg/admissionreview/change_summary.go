@@ -0,0 +1,45 @@
+package admissionreview
+
+import (
+	"context"
+	"encoding/json"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/jsondiff"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+)
+
+// summarizeChange adds the changed JSON paths or the JSON patch computed by diff to the event,
+// if enabled for the module. A patch exceeding the size limit is replaced by the changed paths.
+func (h *Handler) summarizeChange(ctx context.Context, moduleName string, diff func() ([]jsondiff.Operation, error),
+	event *listenerTypes.WatchEvent,
+) {
+	moduleConfig := h.config.ModuleConfig(moduleName)
+	if moduleConfig.ChangeSummary == serverconfig.ChangeSummaryNone {
+		return
+	}
+
+	logger := h.loggerFrom(ctx)
+	resource := event.Watched.String()
+	operations, err := diff()
+	if err != nil {
+		logger.Error(err, "failed to compute changes of watched resource "+resource)
+		return
+	}
+
+	if moduleConfig.ChangeSummary == serverconfig.ChangeSummaryPatch {
+		patch, err := json.Marshal(operations)
+		if err != nil {
+			logger.Error(err, "failed to encode patch of watched resource "+resource)
+			return
+		}
+		if len(patch) <= moduleConfig.MaxPatchSize {
+			event.Patch = patch
+			return
+		}
+		logger.V(1).Info("patch exceeds size limit, sending changed paths instead",
+			"resource", resource, "size", len(patch), "limit", moduleConfig.MaxPatchSize)
+	}
+	event.ChangedPaths = jsondiff.Paths(operations)
+}
@@ -0,0 +1,21 @@
+package admissionreview
+
+import "slices"
+
+// deletionProgressed reports whether an UPDATE starts the deletion of the object by setting its
+// deletionTimestamp, or advances it by removing a finalizer. Such an UPDATE is forwarded regardless of
+// the watched fields, so KCP can react to the teardown before the final DELETE arrives.
+func deletionProgressed(oldObject, object WatchedObject) bool {
+	if object.DeletionTimestamp == nil {
+		return false
+	}
+	if oldObject.DeletionTimestamp == nil {
+		return true
+	}
+	for _, finalizer := range oldObject.Finalizers {
+		if !slices.Contains(object.Finalizers, finalizer) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package admissionreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestDeletionProgressed(t *testing.T) {
+	t.Parallel()
+	deletionTimestamp := metav1.Now()
+	tests := []struct {
+		name      string
+		oldObject Metadata
+		object    Metadata
+		expected  bool
+	}{
+		{
+			name:      "deletionTimestamp is set",
+			oldObject: Metadata{Finalizers: []string{"kyma"}},
+			object:    Metadata{Finalizers: []string{"kyma"}, DeletionTimestamp: &deletionTimestamp},
+			expected:  true,
+		},
+		{
+			name:      "finalizer is removed during deletion",
+			oldObject: Metadata{Finalizers: []string{"kyma", "other"}, DeletionTimestamp: &deletionTimestamp},
+			object:    Metadata{Finalizers: []string{"other"}, DeletionTimestamp: &deletionTimestamp},
+			expected:  true,
+		},
+		{
+			name:      "other update during deletion",
+			oldObject: Metadata{Finalizers: []string{"kyma"}, DeletionTimestamp: &deletionTimestamp},
+			object:    Metadata{Finalizers: []string{"kyma"}, DeletionTimestamp: &deletionTimestamp},
+			expected:  false,
+		},
+		{
+			name:      "finalizer is removed without deletion",
+			oldObject: Metadata{Finalizers: []string{"kyma"}},
+			object:    Metadata{},
+			expected:  false,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected,
+				deletionProgressed(WatchedObject{Metadata: testCase.oldObject}, WatchedObject{Metadata: testCase.object}))
+		})
+	}
+}
@@ -0,0 +1,293 @@
+package admissionreview
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+// maxBestEffortDeliveries limits the deliveries to best-effort destinations running in the background,
+// further deliveries are skipped until one of them completes.
+const maxBestEffortDeliveries = 64
+
+// Start launches the background delivery of events to KCP, the replay of spooled events
+// and the reload of the KCP client certificates.
+func (h *Handler) Start() {
+	if h.queue != nil {
+		h.queue.Start()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.stopBackground = cancel
+	for _, client := range h.kcpClients {
+		h.background.Go(func() { client.Run(ctx) })
+	}
+	if h.spool != nil {
+		h.background.Go(func() { h.replaySpool(ctx) })
+	}
+}
+
+// Shutdown releases debounced and throttled events and waits until pending events are delivered to KCP or ctx expires.
+// Events that cannot be delivered in time are kept in the spool, if it is enabled.
+func (h *Handler) Shutdown(ctx context.Context) error {
+	h.stopBestEffortDeliveries()
+	h.coalescer.Flush(ctx)
+	h.throttled.Flush(ctx)
+
+	var err error
+	if h.queue != nil {
+		err = h.queue.Shutdown(ctx)
+		if err != nil {
+			err = fmt.Errorf("failed to drain forwarding queue: %w", err)
+		}
+	}
+
+	err = errors.Join(err, h.waitForBestEffortDeliveries(ctx))
+	h.stopBackground()
+	h.background.Wait()
+	if h.spool != nil {
+		err = errors.Join(err, h.spool.Close())
+	}
+	return err
+}
+
+// stopBestEffortDeliveries stops starting deliveries to best-effort destinations, so that
+// waitForBestEffortDeliveries only waits for the deliveries already running.
+func (h *Handler) stopBestEffortDeliveries() {
+	h.bestEffortMu.Lock()
+	defer h.bestEffortMu.Unlock()
+	h.bestEffortClosed = true
+}
+
+// waitForBestEffortDeliveries waits until the events sent to best-effort destinations are delivered
+// or ctx expires.
+func (h *Handler) waitForBestEffortDeliveries(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		h.bestEffortDeliveries.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("failed to complete best-effort deliveries: %w", ctx.Err())
+	}
+}
+
+func newWatchEvent(request *admissionv1.AdmissionRequest, watched WatchedObject) listenerTypes.WatchEvent {
+	return listenerTypes.WatchEvent{
+		Watched:    listenerTypes.ObjectKey{Namespace: watched.Namespace, Name: watched.Name},
+		WatchedGvk: metav1.GroupVersionKind(schema.FromAPIVersionAndKind(watched.APIVersion, watched.Kind)),
+		Operation:  listenerTypes.Operation(request.Operation),
+		// identifies the object version, so consumers can drop stale events and detect recreated objects
+		UID:             watched.UID,
+		ResourceVersion: watched.ResourceVersion,
+		Generation:      watched.Generation,
+		Deleting:        request.Operation == admissionv1.Update && watched.DeletionTimestamp != nil,
+		DryRun:          isDryRun(request),
+	}
+}
+
+func isDryRun(request *admissionv1.AdmissionRequest) bool {
+	return request.DryRun != nil && *request.DryRun
+}
+
+// forward delivers the event to KCP within the rate limit of the module, either directly, debounced
+// or through the forwarding queue if it is enabled, and returns the result of the admission request.
+// Dry-run events are never debounced, as they must not replace a pending event of a persisted change.
+// The UID of the admission request identifies the event.
+func (h *Handler) forward(ctx context.Context, request *admissionv1.AdmissionRequest, moduleName string,
+	event listenerTypes.WatchEvent,
+) admissionResult {
+	envelope := kcpevent.Envelope{ModuleName: moduleName, ID: string(request.UID), Event: event}
+
+	var result admissionResult
+	if window := h.config.ModuleConfig(moduleName).DebounceWindow.Duration; window > 0 && !event.DryRun {
+		h.coalescer.Add(envelope, window)
+		result = admissionResult{message: kcpReqDebouncedMsg, outcome: outcomeDebounced}
+	} else {
+		result = h.dispatchWithinRateLimit(ctx, envelope)
+	}
+	result.eventID = envelope.ID
+	return result
+}
+
+// forwardDebounced dispatches an event once its debounce window has elapsed or on shutdown.
+func (h *Handler) forwardDebounced(ctx context.Context, envelope kcpevent.Envelope) {
+	_ = h.dispatchWithinRateLimit(ctx, envelope)
+}
+
+func (h *Handler) dispatch(ctx context.Context, envelope kcpevent.Envelope) admissionResult {
+	if h.queue == nil {
+		envelopes := []kcpevent.Envelope{envelope}
+		err := h.deliver(ctx, envelopes)
+		if err != nil {
+			h.spoolUndelivered(envelopes)
+			return failedWith(err)
+		}
+		return admissionResult{message: kcpReqSucceededMsg, outcome: outcomeDelivered, kcpStatusCode: http.StatusOK}
+	}
+
+	err := h.queue.Enqueue(envelope)
+	if err != nil {
+		err = errors.Join(errKcpRequest, err)
+		h.loggerFrom(ctx).Error(err, "failed to queue event", "postBody", envelope.Event, "eventID", envelope.ID)
+		if h.spoolEvent(envelope) {
+			return admissionResult{message: kcpReqSpooledMsg, outcome: outcomeSpooled}
+		}
+		return failedWith(err)
+	}
+	return admissionResult{message: kcpReqQueuedMsg, outcome: outcomeQueued}
+}
+
+// deliver sends the events to KCP and triggers the replay of spooled events once KCP is reachable again.
+func (h *Handler) deliver(ctx context.Context, envelopes []kcpevent.Envelope) error {
+	err := h.sendToDestinations(ctx, envelopes)
+	if err != nil {
+		return err
+	}
+	if h.spool != nil && h.spool.Len() > 0 {
+		select {
+		case h.replayTrigger <- struct{}{}:
+		default:
+		}
+	}
+	return nil
+}
+
+// sendToDestinations sends the events of a module to its destinations in parallel and returns the errors
+// of the required destinations. Best-effort destinations receive the events in the background, so they
+// neither delay nor fail the delivery.
+func (h *Handler) sendToDestinations(ctx context.Context, envelopes []kcpevent.Envelope) error {
+	if len(envelopes) == 0 {
+		return nil
+	}
+	routes := h.moduleRoutes(envelopes[0].ModuleName)
+	errs := make([]error, len(routes))
+	var deliveries sync.WaitGroup
+	for idx, route := range routes {
+		if route.required() {
+			deliveries.Go(func() { errs[idx] = h.sendToDestination(ctx, route, envelopes) })
+		} else {
+			h.sendToBestEffortDestination(ctx, route, envelopes)
+		}
+	}
+	deliveries.Wait()
+	return errors.Join(errs...)
+}
+
+// sendToBestEffortDestination sends the events to the best-effort destination of the route in the background.
+// The delivery is skipped once Shutdown was called or if maxBestEffortDeliveries are already running.
+func (h *Handler) sendToBestEffortDestination(ctx context.Context, route *route, envelopes []kcpevent.Envelope) {
+	h.bestEffortMu.Lock()
+	defer h.bestEffortMu.Unlock()
+	if h.bestEffortClosed {
+		h.skipBestEffortDelivery(route, "watcher is shutting down")
+		return
+	}
+	select {
+	case h.bestEffortSlots <- struct{}{}:
+	default:
+		h.skipBestEffortDelivery(route, "too many best-effort deliveries running")
+		return
+	}
+	h.bestEffortDeliveries.Go(func() {
+		defer func() { <-h.bestEffortSlots }()
+		_ = h.sendToDestination(context.WithoutCancel(ctx), route, envelopes)
+	})
+}
+
+func (h *Handler) skipBestEffortDelivery(route *route, reason string) {
+	h.logger.V(1).Info("skipped delivery to best-effort destination", "destination", route.destination.Address,
+		"reason", reason)
+	h.metrics.UpdateDestinationDeliveriesTotal(route.destination.Address, string(route.destination.Mode),
+		watchermetrics.DeliverySkipped)
+}
+
+// sendToDestination sends the events to the destination of the route and counts the outcome per destination.
+func (h *Handler) sendToDestination(ctx context.Context, route *route, envelopes []kcpevent.Envelope) error {
+	err := h.sendThroughBreaker(ctx, route, envelopes)
+	result := watchermetrics.DeliverySucceeded
+	if err != nil {
+		result = watchermetrics.DeliveryFailed
+	}
+	h.metrics.UpdateDestinationDeliveriesTotal(route.destination.Address, string(route.destination.Mode), result)
+	return err
+}
+
+// sendThroughBreaker sends the events to the destination of the route unless its circuit breaker is open,
+// in which case it fails fast.
+func (h *Handler) sendThroughBreaker(ctx context.Context, route *route, envelopes []kcpevent.Envelope) error {
+	if route.breaker == nil {
+		return h.sendRequestToKcp(ctx, route, envelopes)
+	}
+	err := route.breaker.Allow()
+	if err != nil {
+		return errors.Join(errKcpRequest, err)
+	}
+	err = h.sendRequestToKcp(ctx, route, envelopes)
+	if err != nil && ctx.Err() != nil {
+		// a request cancelled by the caller says nothing about the state of KCP
+		route.breaker.Release()
+		return err
+	}
+	route.breaker.Done(err)
+	return err
+}
+
+// spoolUndelivered keeps events that could not be delivered in the spool, if it is enabled.
+func (h *Handler) spoolUndelivered(envelopes []kcpevent.Envelope) {
+	for _, envelope := range envelopes {
+		h.spoolEvent(envelope)
+	}
+}
+
+// spoolEvent keeps the event in the spool and reports whether it was kept. It returns false if the spool
+// is disabled.
+func (h *Handler) spoolEvent(envelope kcpevent.Envelope) bool {
+	if h.spool == nil {
+		return false
+	}
+	err := h.spool.Append(envelope)
+	if err != nil {
+		h.logger.Error(err, "failed to spool undelivered event", "postBody", envelope.Event)
+		return false
+	}
+	h.logger.Info("spooled undelivered event for resource "+envelope.Event.Watched.String(),
+		"module", envelope.ModuleName)
+	return true
+}
+
+// replaySpool delivers spooled events on startup, periodically and after a successful delivery.
+func (h *Handler) replaySpool(ctx context.Context) {
+	ticker := time.NewTicker(h.config.SpoolReplayInterval)
+	defer ticker.Stop()
+	for {
+		if h.spool.Len() > 0 {
+			err := h.spool.Replay(ctx, h.sendToDestinations)
+			if err != nil {
+				h.logger.Error(err, "failed to replay spooled events", "remaining", h.spool.Len())
+			}
+		}
+		h.spool.UpdateMetrics()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-h.replayTrigger:
+		}
+	}
+}
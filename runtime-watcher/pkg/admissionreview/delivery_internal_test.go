@@ -0,0 +1,322 @@
+package admissionreview
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/requestparser"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+// kcpServer is a KCP gateway counting the requests it receives.
+type kcpServer struct {
+	address  string
+	requests atomic.Int32
+	eventIDs atomic.Value
+}
+
+func newKCPServer(t *testing.T, certProvider *tlstest.CertProvider, statusCode int) *kcpServer {
+	t.Helper()
+	return newKCPServerFunc(t, certProvider, func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(statusCode)
+	})
+}
+
+// newKCPServerFunc creates a KCP gateway answering every request with respond.
+func newKCPServerFunc(t *testing.T, certProvider *tlstest.CertProvider, respond http.HandlerFunc) *kcpServer {
+	t.Helper()
+	rootCert, err := x509.ParseCertificate(certProvider.RootCert.Certificate[0])
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(rootCert)
+
+	kcp := &kcpServer{}
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		kcp.requests.Add(1)
+		kcp.eventIDs.Store(request.Header.Get(listenerTypes.EventIDHeader))
+		respond(writer, request)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{*certProvider.ServerCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	serverURL, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	kcp.address = serverURL.Host
+	return kcp
+}
+
+// newDeliveryHandler creates a handler delivering to the primary KCP server. configure may change the config.
+func newDeliveryHandler(t *testing.T, certProvider *tlstest.CertProvider, primary *kcpServer,
+	configure func(config *serverconfig.ServerConfig),
+) *Handler {
+	t.Helper()
+	config := serverconfig.ServerConfig{
+		CACertPath:         certProvider.RootCertFile.Name(),
+		TLSCertPath:        certProvider.ClientCertFile.Name(),
+		TLSKeyPath:         certProvider.ClientKeyFile.Name(),
+		KCPAddress:         primary.address,
+		KCPContract:        "v2",
+		CertReloadInterval: time.Minute,
+	}
+	if configure != nil {
+		configure(&config)
+	}
+	handler, err := NewHandler(logr.Discard(), config, requestparser.RequestParser{}, *watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	return handler
+}
+
+func newFanOutHandler(t *testing.T, certProvider *tlstest.CertProvider, primary *kcpServer,
+	shadows ...serverconfig.Destination,
+) *Handler {
+	t.Helper()
+	return newDeliveryHandler(t, certProvider, primary, func(config *serverconfig.ServerConfig) {
+		config.Modules = map[string]serverconfig.ModuleConfig{"kyma": {ShadowDestinations: shadows}}
+	})
+}
+
+func TestSendToDestinations(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	envelopes := []kcpevent.Envelope{{
+		ModuleName: "kyma",
+		Event:      listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: "kyma"}},
+	}}
+
+	t.Run("failing best-effort destination does not fail delivery", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusOK)
+		shadow := newKCPServer(t, certProvider, http.StatusBadRequest)
+		handler := newFanOutHandler(t, certProvider, primary, serverconfig.Destination{Address: shadow.address})
+
+		err := handler.sendToDestinations(t.Context(), envelopes)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), primary.requests.Load())
+		assert.Eventually(t, func() bool { return shadow.requests.Load() == 1 }, time.Second, 10*time.Millisecond)
+		require.NoError(t, handler.waitForBestEffortDeliveries(t.Context()))
+	})
+
+	t.Run("failing required destination fails delivery", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusOK)
+		shadow := newKCPServer(t, certProvider, http.StatusBadRequest)
+		handler := newFanOutHandler(t, certProvider, primary,
+			serverconfig.Destination{Address: shadow.address, Mode: serverconfig.DeliveryRequired})
+
+		err := handler.sendToDestinations(t.Context(), envelopes)
+
+		require.Error(t, err)
+		assert.Equal(t, int32(1), primary.requests.Load())
+		assert.Equal(t, int32(1), shadow.requests.Load())
+	})
+
+	t.Run("best-effort destination receives events when required destination fails", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusBadRequest)
+		shadow := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newFanOutHandler(t, certProvider, primary, serverconfig.Destination{Address: shadow.address})
+
+		err := handler.sendToDestinations(t.Context(), envelopes)
+
+		require.Error(t, err)
+		require.NoError(t, handler.waitForBestEffortDeliveries(t.Context()))
+		assert.Equal(t, int32(1), shadow.requests.Load())
+	})
+
+	t.Run("best-effort destination is skipped when too many deliveries are running", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusOK)
+		shadow := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newFanOutHandler(t, certProvider, primary, serverconfig.Destination{Address: shadow.address})
+		for range maxBestEffortDeliveries {
+			handler.bestEffortSlots <- struct{}{}
+		}
+
+		err := handler.sendToDestinations(t.Context(), envelopes)
+
+		require.NoError(t, err)
+		require.NoError(t, handler.waitForBestEffortDeliveries(t.Context()))
+		assert.Equal(t, int32(1), primary.requests.Load())
+		assert.Zero(t, shadow.requests.Load())
+	})
+
+	t.Run("best-effort destination is skipped after shutdown", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusOK)
+		shadow := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newFanOutHandler(t, certProvider, primary, serverconfig.Destination{Address: shadow.address})
+		require.NoError(t, handler.Shutdown(t.Context()))
+
+		err := handler.sendToDestinations(t.Context(), envelopes)
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), primary.requests.Load())
+		assert.Zero(t, shadow.requests.Load())
+	})
+}
+
+func TestForward_IdentifiesEventByAdmissionRequestUID(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	request := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800002"}
+	event := listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: "kyma"}}
+
+	t.Run("delivered event", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newFanOutHandler(t, certProvider, primary)
+
+		result := handler.forward(t.Context(), request, "kyma", event)
+
+		assert.Equal(t, map[string]string{
+			auditAnnotationEventID:       string(request.UID),
+			auditAnnotationOutcome:       string(outcomeDelivered),
+			auditAnnotationKcpStatusCode: "200",
+		}, result.auditAnnotations())
+		assert.Equal(t, string(request.UID), primary.eventIDs.Load())
+	})
+
+	t.Run("event rejected by KCP", func(t *testing.T) {
+		t.Parallel()
+		primary := newKCPServer(t, certProvider, http.StatusBadRequest)
+		handler := newFanOutHandler(t, certProvider, primary)
+
+		result := handler.forward(t.Context(), request, "kyma", event)
+
+		assert.Equal(t, string(request.UID), result.eventID)
+		assert.Equal(t, outcomeFailed, result.outcome)
+		assert.Equal(t, http.StatusBadRequest, result.kcpStatusCode)
+		assert.Equal(t, string(request.UID), primary.eventIDs.Load())
+	})
+}
+
+func TestForward_QueueRetriesFailedDeliveriesWithSingleRequests(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	primary := newKCPServer(t, certProvider, http.StatusInternalServerError)
+	handler := newDeliveryHandler(t, certProvider, primary, func(config *serverconfig.ServerConfig) {
+		config.ForwardingWorkers = 1
+		config.ForwardingQueueSize = 1
+		config.ForwardingMaxRetries = 2
+		config.ForwardingRetryBackoff = time.Millisecond
+	})
+	handler.Start()
+	request := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800002"}
+	event := listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: "kyma"}}
+
+	result := handler.forward(t.Context(), request, "kyma", event)
+
+	assert.Equal(t, outcomeQueued, result.outcome)
+	require.NoError(t, handler.Shutdown(t.Context()))
+	assert.Equal(t, int32(3), primary.requests.Load())
+}
+
+func TestShutdown_DeliversOrSpoolsPendingEventsWithinDeadline(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	request := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800002"}
+	event := listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: "kyma"}}
+	debounceAndSpool := func(config *serverconfig.ServerConfig) {
+		config.Modules = map[string]serverconfig.ModuleConfig{
+			"kyma": {DebounceWindow: metav1.Duration{Duration: time.Hour}},
+		}
+		config.SpoolDir = t.TempDir()
+		config.SpoolMaxBytes = 1024 * 1024
+		config.SpoolMaxAge = time.Hour
+	}
+
+	t.Run("debounced event is delivered", func(t *testing.T) {
+		t.Parallel()
+		kcp := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newDeliveryHandler(t, certProvider, kcp, debounceAndSpool)
+		assert.Equal(t, outcomeDebounced, handler.forward(t.Context(), request, "kyma", event).outcome)
+
+		require.NoError(t, handler.Shutdown(t.Context()))
+
+		assert.Equal(t, int32(1), kcp.requests.Load())
+		assert.Zero(t, handler.spool.Len())
+	})
+
+	t.Run("debounced event is spooled when KCP does not answer in time", func(t *testing.T) {
+		t.Parallel()
+		unblock := make(chan struct{})
+		kcp := newKCPServerFunc(t, certProvider, func(http.ResponseWriter, *http.Request) { <-unblock })
+		t.Cleanup(func() { close(unblock) })
+		handler := newDeliveryHandler(t, certProvider, kcp, debounceAndSpool)
+		assert.Equal(t, outcomeDebounced, handler.forward(t.Context(), request, "kyma", event).outcome)
+		ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+		defer cancel()
+
+		start := time.Now()
+		_ = handler.Shutdown(ctx)
+
+		assert.Less(t, time.Since(start), time.Second)
+		assert.Positive(t, kcp.requests.Load())
+		assert.Equal(t, 1, handler.spool.Len())
+	})
+}
+
+func TestForward_SpoolsEventsWhenQueueIsFull(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	unblock := make(chan struct{})
+	kcp := newKCPServerFunc(t, certProvider, func(http.ResponseWriter, *http.Request) { <-unblock })
+	t.Cleanup(func() { close(unblock) })
+	handler := newDeliveryHandler(t, certProvider, kcp, func(config *serverconfig.ServerConfig) {
+		config.ForwardingWorkers = 1
+		config.ForwardingQueueSize = 1
+		config.SpoolDir = t.TempDir()
+		config.SpoolMaxBytes = 1024 * 1024
+		config.SpoolMaxAge = time.Hour
+		config.SpoolReplayInterval = time.Hour
+	})
+	handler.Start()
+	request := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800002"}
+	event := listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: "kyma"}}
+
+	assert.Equal(t, outcomeQueued, handler.forward(t.Context(), request, "kyma", event).outcome)
+	require.Eventually(t, func() bool { return kcp.requests.Load() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, outcomeQueued, handler.forward(t.Context(), request, "kyma", event).outcome)
+	result := handler.forward(t.Context(), request, "kyma", event)
+
+	assert.Equal(t, outcomeSpooled, result.outcome)
+	assert.NoError(t, result.problem)
+	assert.Equal(t, 1, handler.spool.Len())
+	ctx, cancel := context.WithTimeout(t.Context(), 100*time.Millisecond)
+	defer cancel()
+	_ = handler.Shutdown(ctx)
+}
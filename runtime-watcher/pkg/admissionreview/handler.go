@@ -1,8 +1,8 @@
 package admissionreview
 
 import (
-	"bytes"
-	"crypto/tls"
+	"context"
+	"crypto/rand"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -10,19 +10,20 @@ import (
 	"net/http"
 	"reflect"
 	"strings"
+	"sync"
 	"time"
 
-	"github.com/sethgrid/pester"
-
 	admissionv1 "k8s.io/api/admission/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
-	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"github.com/go-logr/logr"
 
-	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
-
-	"github.com/kyma-project/runtime-watcher/skr/pkg/cacertificatehandler"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/eventcoalescer"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/eventqueue"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/eventspool"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/jsondiff"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpclient"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/ratelimit"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/requestparser"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
@@ -34,6 +35,9 @@ const (
 	admissionError     = "admission error"
 	kcpReqFailedMsg    = "kcp request failed"
 	kcpReqSucceededMsg = "kcp request succeeded"
+	kcpReqQueuedMsg    = "kcp request queued"
+	kcpReqDebouncedMsg = "kcp request debounced"
+	kcpReqSpooledMsg   = "kcp request spooled"
 	urlPathPattern     = "/validate/%s"
 	statusSubResource  = "status"
 )
@@ -53,19 +57,70 @@ type Handler struct {
 	config        serverconfig.ServerConfig
 	requestParser requestparser.RequestParser
 	metrics       watchermetrics.WatcherMetrics
+	queue         *eventqueue.Queue
+	coalescer     *eventcoalescer.Coalescer
+	throttled     *eventcoalescer.Coalescer
+	rateLimiters  map[string]*ratelimit.Limiter
+	spool         *eventspool.Spool
+	routes        map[serverconfig.Destination]*route
+	kcpClients    []*kcpclient.Client
+
+	replayTrigger        chan struct{}
+	stopBackground       context.CancelFunc
+	background           sync.WaitGroup
+	bestEffortDeliveries sync.WaitGroup
+	bestEffortSlots      chan struct{}
+	bestEffortMu         sync.Mutex
+	bestEffortClosed     bool
 }
 
 func NewHandler(logger logr.Logger,
 	config serverconfig.ServerConfig,
 	parser requestparser.RequestParser,
 	metrics watchermetrics.WatcherMetrics,
-) *Handler {
-	return &Handler{
-		logger:        logger,
-		config:        config,
-		requestParser: parser,
-		metrics:       metrics,
+) (*Handler, error) {
+	handler := &Handler{
+		logger:          logger,
+		config:          config,
+		requestParser:   parser,
+		metrics:         metrics,
+		replayTrigger:   make(chan struct{}, 1),
+		bestEffortSlots: make(chan struct{}, maxBestEffortDeliveries),
+		stopBackground:  func() {},
+	}
+	err := handler.newRoutes()
+	if err != nil {
+		return nil, err
+	}
+	if config.SpoolDir != "" {
+		spool, err := eventspool.Open(logger.WithName("spool"), eventspool.Config{
+			Dir:      config.SpoolDir,
+			MaxBytes: int64(config.SpoolMaxBytes),
+			MaxAge:   config.SpoolMaxAge,
+		}, &handler.metrics)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open spool: %w", err)
+		}
+		handler.spool = spool
+	}
+	if config.ForwardingWorkers > 0 {
+		// batches are split into single requests under older contracts, so retries would repeat delivered events
+		batchSize := 1
+		if handler.batchSupported() {
+			batchSize = config.ForwardingBatchSize
+		}
+		handler.queue = eventqueue.New(logger.WithName("forwarding-queue"), eventqueue.Config{
+			Size:         config.ForwardingQueueSize,
+			Workers:      config.ForwardingWorkers,
+			MaxRetries:   config.ForwardingMaxRetries,
+			RetryBackoff: config.ForwardingRetryBackoff,
+			BatchSize:    batchSize,
+		}, handler.deliver, handler.spoolUndelivered, &handler.metrics)
 	}
+	handler.coalescer = eventcoalescer.New(handler.forwardDebounced, &handler.metrics)
+	handler.throttled = eventcoalescer.New(handler.forwardThrottled, &handler.metrics)
+	handler.rateLimiters = newRateLimiters(config.Modules)
+	return handler, nil
 }
 
 const (
@@ -78,45 +133,81 @@ const (
 
 var errEmptyConfig = errors.New("KCPAddress or KCPContract empty")
 
+// Handle answers every admission review with an allowed response, reporting problems of the watcher as
+// warning and audit annotation, so they never block a change in SKR. Requests that do not contain
+// an admission review are rejected with an HTTP error status.
 func (h *Handler) Handle(writer http.ResponseWriter, request *http.Request) {
-	h.logger.Info("Handle request - START")
+	logger := h.logger.WithValues("requestID", rand.Text())
+	logger.V(2).Info("Handle request - START")
 	h.metrics.UpdateAdmissionRequestsTotal()
 	start := time.Now()
+	statusCode, err := validateHTTPRequest(request)
+	if err != nil {
+		if statusCode == http.StatusMethodNotAllowed {
+			writer.Header().Set(allowHeader, http.MethodPost)
+		}
+		h.rejectRequest(logger, writer, statusCode, err)
+		return
+	}
 	admissionReview, err := h.requestParser.ParseAdmissionReview(request)
 	if err != nil {
-		h.logger.Error(errors.Join(errAdmission, err), "failed to parse AdmissionReview")
-		h.metrics.UpdateAdmissionRequestsErrorTotal()
+		h.rejectRequest(logger, writer, http.StatusBadRequest, err)
 		return
 	}
 
-	h.logger.Info("Incoming admission review for: " + admissionReview.Request.Kind.String())
+	logger = logger.WithValues("uid", admissionReview.Request.UID)
+	logger.V(1).Info("Incoming admission review for: " + admissionReview.Request.Kind.String())
 
+	var result admissionResult
 	moduleName, err := getModuleName(request.URL.Path)
 	if err != nil {
-		h.logger.Error(err, "failed to get module name")
-		return
+		logger.Error(err, "failed to get module name")
+		result = failedWith(err)
+	} else {
+		logger = logger.WithValues("module", moduleName)
+		ctx := logr.NewContext(request.Context(), logger)
+		result = h.validateResources(ctx, admissionReview.Request, moduleName)
+		result.module = moduleName
 	}
+	logger.V(1).Info(result.message, "outcome", result.outcome, "eventID", result.eventID)
 
-	validationMsg := h.validateResources(admissionReview.Request, moduleName)
-	h.logger.Info(validationMsg)
-
-	responseBytes := h.prepareResponse(admissionReview, validationMsg)
+	responseBytes := h.prepareResponse(logger, admissionReview, result)
 	if responseBytes == nil {
-		h.logger.Info("Empty response from incoming admission review")
+		h.rejectRequest(logger, writer, http.StatusInternalServerError, errAdmission)
 		return
 	}
 
 	writer.Header().Set(strictTransportSecurityHeader, strictTransportSecurityValue)
 	writer.Header().Set(contentSecurityPolicy, contentSecurityPolicyValue)
+	writer.Header().Set(contentTypeHeader, jsonContentType)
 	_, err = writer.Write(responseBytes)
 	if err != nil {
-		h.logger.Error(err, admissionError)
+		logger.Error(err, admissionError)
 		return
 	}
 
 	duration := time.Since(start)
 	h.metrics.UpdateRequestDuration(duration)
-	h.logger.Info("Handle request - END")
+	logger.V(2).Info("Handle request - END", "duration", duration)
+}
+
+// loggerFrom returns the logger of the admission request handled with ctx, which identifies the request,
+// or the logger of the handler for deliveries outside of an admission request.
+func (h *Handler) loggerFrom(ctx context.Context) logr.Logger {
+	logger, err := logr.FromContext(ctx)
+	if err != nil {
+		return h.logger
+	}
+	return logger
+}
+
+// rejectRequest answers a request without admission review with an HTTP error status.
+func (h *Handler) rejectRequest(logger logr.Logger, writer http.ResponseWriter, statusCode int, err error) {
+	logger.Error(errors.Join(errAdmission, err), "failed to parse AdmissionReview")
+	h.metrics.UpdateAdmissionRequestsErrorTotal()
+	writer.Header().Set(strictTransportSecurityHeader, strictTransportSecurityValue)
+	writer.Header().Set(contentSecurityPolicy, contentSecurityPolicyValue)
+	http.Error(writer, err.Error(), statusCode)
 }
 
 func getModuleName(urlPath string) (string, error) {
@@ -133,13 +224,13 @@ func getModuleName(urlPath string) (string, error) {
 	return moduleName, nil
 }
 
-func (h *Handler) prepareResponse(admissionReview *admissionv1.AdmissionReview,
-	validationMessage string,
+func (h *Handler) prepareResponse(logger logr.Logger, admissionReview *admissionv1.AdmissionReview,
+	result admissionResult,
 ) []byte {
-	h.logger.Info(fmt.Sprintf("Preparing response for AdmissionReview: %s %s %s",
+	logger.V(2).Info(fmt.Sprintf("Preparing response for AdmissionReview: %s %s %s",
 		admissionReview.Request.Kind.Kind,
 		string(admissionReview.Request.Operation),
-		validationMessage))
+		result.message))
 
 	finalizedAdmissionReview := admissionv1.AdmissionReview{
 		TypeMeta: metav1.TypeMeta{
@@ -150,28 +241,48 @@ func (h *Handler) prepareResponse(admissionReview *admissionv1.AdmissionReview,
 			UID:     admissionReview.Request.UID,
 			Allowed: true,
 			Result: &metav1.Status{
-				Message: validationMessage,
+				Message: result.message,
 				Status:  metav1.StatusSuccess,
 			},
 		},
 	}
+	finalizedAdmissionReview.Response.AuditAnnotations = result.auditAnnotations()
+	if result.problem != nil {
+		finalizedAdmissionReview.Response.Warnings = []string{result.warning()}
+	}
 
-	admissionReviewBytes, err := json.Marshal(&finalizedAdmissionReview)
+	admissionReviewBytes, err := requestparser.MarshalAdmissionReview(&finalizedAdmissionReview)
 	if err != nil {
-		h.logger.Error(err, admissionError)
+		logger.Error(err, admissionError)
 		return nil
 	}
 	return admissionReviewBytes
 }
 
-func (h *Handler) validateResources(request *admissionv1.AdmissionRequest, moduleName string,
-) string {
+func (h *Handler) validateResources(ctx context.Context, request *admissionv1.AdmissionRequest,
+	moduleName string,
+) admissionResult {
+	if isDryRun(request) && !h.config.ModuleConfig(moduleName).ForwardDryRun {
+		h.metrics.UpdateDryRunSkippedTotal(moduleName)
+		return resultOf(fmt.Sprintf("dry-run request not forwarded for watched resource %s/%s",
+			request.Namespace, request.Name))
+	}
+	if h.userFiltered(moduleName, request.UserInfo) {
+		return resultOf(fmt.Sprintf("change by user %s not forwarded for watched resource %s/%s",
+			request.UserInfo.Username, request.Namespace, request.Name))
+	}
 	object, oldObject := WatchedObject{}, WatchedObject{}
 
 	switch request.Operation {
 	case admissionv1.Update:
-		h.unmarshalWatchedObject(request.OldObject.Raw, &oldObject)
-		h.unmarshalWatchedObject(request.Object.Raw, &object)
+		h.unmarshalWatchedObject(ctx, request.OldObject.Raw, &oldObject)
+		h.unmarshalWatchedObject(ctx, request.Object.Raw, &object)
+		if deletionProgressed(oldObject, object) {
+			return h.forward(ctx, request, moduleName, newWatchEvent(request, object))
+		}
+		if paths := h.config.ModuleConfig(moduleName).WatchedFieldPaths(); len(paths) > 0 {
+			return h.validateWatchedFields(ctx, request, moduleName, paths, oldObject, object)
+		}
 		resource := &Resource{
 			GroupVersionKind: request.Kind,
 			SubResource:      request.SubResource,
@@ -179,32 +290,32 @@ func (h *Handler) validateResources(request *admissionv1.AdmissionRequest, modul
 		changed, err := h.checkForChange(resource, oldObject, object)
 		if err != nil {
 			h.metrics.UpdateFailedKCPTotal(watchermetrics.ReasonSubresource)
-			return err.Error()
+			return failedWith(err)
 		}
 		if !changed {
-			return fmt.Sprintf("no change detected on watched resource %s/%s",
-				object.Namespace, object.Name)
+			return resultOf(noChangeMessage(object))
 		}
-		err = h.sendRequestToKcp(moduleName, object)
-		if err != nil {
-			return err.Error()
+		if h.onlyIgnoredFieldsChanged(moduleName, resource, &oldObject, &object) {
+			return resultOf(suppressedMessage(object))
 		}
+		event := newWatchEvent(request, object)
+		h.summarizeChange(ctx, moduleName, func() ([]jsondiff.Operation, error) {
+			if strings.ToLower(resource.SubResource) == statusSubResource {
+				return jsondiff.Diff("/status", oldObject.Status, object.Status)
+			}
+			return jsondiff.Diff("/spec", oldObject.Spec, object.Spec)
+		}, &event)
+		return h.forward(ctx, request, moduleName, event)
 	case admissionv1.Delete:
-		h.unmarshalWatchedObject(request.OldObject.Raw, &oldObject)
-		err := h.sendRequestToKcp(moduleName, oldObject)
-		if err != nil {
-			return err.Error()
-		}
+		h.unmarshalWatchedObject(ctx, request.OldObject.Raw, &oldObject)
+		return h.forward(ctx, request, moduleName, newWatchEvent(request, oldObject))
 	case admissionv1.Create:
-		h.unmarshalWatchedObject(request.Object.Raw, &object)
-		err := h.sendRequestToKcp(moduleName, object)
-		if err != nil {
-			return err.Error()
-		}
+		h.unmarshalWatchedObject(ctx, request.Object.Raw, &object)
+		return h.forward(ctx, request, moduleName, newWatchEvent(request, object))
 	case admissionv1.Connect:
-		return fmt.Sprintf("operation %s not supported for %s", admissionv1.Connect, request.Kind.String())
+		return resultOf(fmt.Sprintf("operation %s not supported for %s", admissionv1.Connect, request.Kind.String()))
 	}
-	return kcpReqSucceededMsg
+	return resultOf(kcpReqSucceededMsg)
 }
 
 var (
@@ -212,13 +323,13 @@ var (
 	errKcpRequest = errors.New(kcpReqFailedMsg)
 )
 
-func (h *Handler) unmarshalWatchedObject(rawBytes []byte, response responseInterface) {
+func (h *Handler) unmarshalWatchedObject(ctx context.Context, rawBytes []byte, response responseInterface) {
 	err := json.Unmarshal(rawBytes, response)
 	if err != nil {
-		h.logger.Error(errors.Join(errAdmission, err), "failed to unmarshal admission review resource object")
+		h.loggerFrom(ctx).Error(errors.Join(errAdmission, err), "failed to unmarshal admission review resource object")
 	}
 	if response.IsEmpty() {
-		h.logger.Error(errAdmission, "admission review resource object is empty")
+		h.loggerFrom(ctx).Error(errAdmission, "admission review resource object is empty")
 	}
 }
 
@@ -246,93 +357,3 @@ func (h *Handler) checkForChange(resource *Resource, oldObj, obj WatchedObject)
 
 	return registerChange, nil
 }
-
-func (h *Handler) sendRequestToKcp(moduleName string, watched WatchedObject) error {
-	h.metrics.UpdateKCPTotal()
-
-	watcherEvent := &listenerTypes.WatchEvent{
-		Watched:    listenerTypes.ObjectKey{Namespace: watched.Namespace, Name: watched.Name},
-		WatchedGvk: metav1.GroupVersionKind(schema.FromAPIVersionAndKind(watched.APIVersion, watched.Kind)),
-	}
-
-	if h.config.KCPAddress == "" || h.config.KCPContract == "" {
-		return h.logAndReturnKCPErr(errEmptyConfig, watchermetrics.ReasonKcpAddress)
-	}
-
-	url := fmt.Sprintf("https://%s/%s/%s/%s", h.config.KCPAddress, h.config.KCPContract, moduleName, eventEndpoint)
-	httpsClient, err := h.getHTTPSClient()
-	if err != nil {
-		return h.logAndReturnKCPErr(err, watchermetrics.ReasonRequest)
-	}
-
-	resilientClient := pester.NewExtendedClient(httpsClient)
-	resilientClient.Backoff = pester.ExponentialBackoff
-	resilientClient.MaxRetries = 3
-	resilientClient.KeepLog = true
-
-	postBody, err := json.Marshal(watcherEvent)
-	if err != nil {
-		return h.logAndReturnKCPErr(err, watchermetrics.ReasonRequest)
-	}
-	resp, err := resilientClient.Post(url, "application/json", bytes.NewBuffer(postBody))
-	if err != nil {
-		err = errors.Join(errKcpRequest, err)
-		h.logger.Error(err, resilientClient.LogString(), "postBody", watcherEvent)
-		h.metrics.UpdateFailedKCPTotal(watchermetrics.ReasonResponse)
-		return err
-	}
-	for range resilientClient.SuccessRetryNum - 1 {
-		h.metrics.UpdateFailedKCPTotal(watchermetrics.ReasonResponse)
-	}
-	defer resp.Body.Close()
-	responseBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		err = errors.Join(errKcpRequest, err)
-		h.logger.Error(err, err.Error(), "postBody", watcherEvent)
-		h.metrics.UpdateFailedKCPTotal(watchermetrics.ReasonResponse)
-		return err
-	}
-	if resp.StatusCode != http.StatusOK {
-		err = fmt.Errorf("%w: responseBody: %s with StatusCode: %d", errKcpRequest, responseBody, resp.StatusCode)
-		h.logger.Error(err, err.Error(), "postBody", watcherEvent)
-		h.metrics.UpdateFailedKCPTotal(watchermetrics.ReasonResponse)
-		return err
-	}
-
-	h.logger.Info(fmt.Sprintf("sent request to KCP successfully for resource %s/%s",
-		watched.Namespace, watched.Name), "postBody", watcherEvent)
-	return nil
-}
-
-func (h *Handler) logAndReturnKCPErr(err error, reason watchermetrics.KcpErrReason) error {
-	err = errors.Join(errKcpRequest, err)
-	h.logger.Error(err, err.Error())
-	h.metrics.UpdateFailedKCPTotal(reason)
-	return err
-}
-
-func (h *Handler) getHTTPSClient() (*http.Client, error) {
-	httpsClient := http.Client{}
-
-	certificate, err := tls.LoadX509KeyPair(h.config.TLSCertPath, h.config.TLSKeyPath)
-	if err != nil {
-		msg := "could not load tls certificate"
-		return nil, fmt.Errorf("%s :%w", msg, err)
-	}
-
-	rootCertPool, err := cacertificatehandler.GetCertificatePool(h.config.CACertPath)
-	if err != nil {
-		return nil, fmt.Errorf("failed to get certificate pool:%w", err)
-	}
-
-	httpsClient.Timeout = HTTPTimeout
-	//nolint:gosec
-	httpsClient.Transport = &http.Transport{
-		TLSClientConfig: &tls.Config{
-			Certificates: []tls.Certificate{certificate},
-			RootCAs:      rootCertPool,
-		},
-	}
-
-	return &httpsClient, nil
-}
@@ -0,0 +1,165 @@
+package admissionreview
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/requestparser"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+const connectReview = `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview","request":{
+	"uid":"705ab4f5-6393-11e8-b7cc-42010a800002","operation":"CONNECT",
+	"kind":{"group":"operator.kyma-project.io","version":"v1beta2","kind":"Kyma"}
+}}`
+
+func newTestHandler() *Handler {
+	decoder := serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
+	return &Handler{
+		logger:        logr.Discard(),
+		requestParser: *requestparser.NewRequestParser(decoder),
+		metrics:       *watchermetrics.NewMetrics(),
+	}
+}
+
+func TestHandle_RejectsRequestsWithoutAdmissionReview(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name        string
+		method      string
+		contentType string
+		body        string
+		statusCode  int
+	}{
+		{"non-POST method", http.MethodGet, "application/json", connectReview, http.StatusMethodNotAllowed},
+		{"wrong content type", http.MethodPost, "text/plain", connectReview, http.StatusUnsupportedMediaType},
+		{"missing content type", http.MethodPost, "", connectReview, http.StatusUnsupportedMediaType},
+		{"undecodable body", http.MethodPost, "application/json", `{"request":`, http.StatusBadRequest},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			request := httptest.NewRequestWithContext(t.Context(), testCase.method, "/validate/kyma",
+				bytes.NewBufferString(testCase.body))
+			request.Header.Set("Content-Type", testCase.contentType)
+			recorder := httptest.NewRecorder()
+
+			newTestHandler().Handle(recorder, request)
+
+			assert.Equal(t, testCase.statusCode, recorder.Code)
+		})
+	}
+}
+
+func TestHandle_AllowsReviewWithWarningOnInvalidPath(t *testing.T) {
+	t.Parallel()
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/validate/",
+		bytes.NewBufferString(connectReview))
+	request.Header.Set("Content-Type", "application/json; charset=utf-8")
+	recorder := httptest.NewRecorder()
+
+	newTestHandler().Handle(recorder, request)
+
+	require.Equal(t, http.StatusOK, recorder.Code)
+	review := admissionv1.AdmissionReview{}
+	require.NoError(t, json.Unmarshal(recorder.Body.Bytes(), &review))
+	require.NotNil(t, review.Response)
+	assert.True(t, review.Response.Allowed)
+	assert.Equal(t, "705ab4f5-6393-11e8-b7cc-42010a800002", string(review.Response.UID))
+	assert.Equal(t, []string{"runtime-watcher: module name must not be empty"}, review.Response.Warnings)
+	assert.Equal(t, "module name must not be empty", review.Response.AuditAnnotations["error"])
+	assert.Equal(t, "failed", review.Response.AuditAnnotations["outcome"])
+}
+
+func TestHandle_LogsWithRequestIDAndUID(t *testing.T) {
+	t.Parallel()
+	var lines []string
+	handler := newTestHandler()
+	handler.logger = funcr.New(func(_, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{Verbosity: 2})
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/validate/kyma",
+		bytes.NewBufferString(connectReview))
+	request.Header.Set("Content-Type", "application/json")
+
+	handler.Handle(httptest.NewRecorder(), request)
+
+	require.NotEmpty(t, lines)
+	requestID := regexp.MustCompile(`"requestID"="([A-Z2-7]+)"`).FindStringSubmatch(lines[0])
+	require.Len(t, requestID, 2)
+	for _, line := range lines {
+		assert.Contains(t, line, requestID[0])
+	}
+	assert.Contains(t, lines[len(lines)-1], `"uid"="705ab4f5-6393-11e8-b7cc-42010a800002" "module"="kyma"`)
+}
+
+func TestHandle_LogsOnlyProblemsByDefault(t *testing.T) {
+	t.Parallel()
+	var lines []string
+	handler := newTestHandler()
+	handler.logger = funcr.New(func(_, args string) {
+		lines = append(lines, args)
+	}, funcr.Options{})
+	request := httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/validate/kyma",
+		bytes.NewBufferString(connectReview))
+	request.Header.Set("Content-Type", "application/json")
+
+	handler.Handle(httptest.NewRecorder(), request)
+
+	assert.Empty(t, lines)
+}
+
+func TestAdmissionResult_WarningIsSingleLineOfLimitedLength(t *testing.T) {
+	t.Parallel()
+	result := failedWith(fmt.Errorf("%w: %s", errors.Join(errKcpRequest, errAdmission), strings.Repeat("x", 300)))
+
+	warning := result.warning()
+
+	assert.NotContains(t, warning, "\n")
+	assert.Len(t, warning, maxWarningLength)
+	assert.True(t, strings.HasPrefix(warning, "runtime-watcher: kcp request failed: admission error: xxx"))
+}
+
+func TestValidateResources_SkipsDryRunRequests(t *testing.T) {
+	t.Parallel()
+	handler := &Handler{
+		logger:  logr.Discard(),
+		metrics: *watchermetrics.NewMetrics(),
+	}
+	dryRun := true
+
+	result := handler.validateResources(t.Context(), &admissionv1.AdmissionRequest{
+		Operation: admissionv1.Create,
+		Namespace: "kcp-system",
+		Name:      "kyma",
+		DryRun:    &dryRun,
+	}, "kyma")
+
+	assert.Equal(t, "dry-run request not forwarded for watched resource kcp-system/kyma", result.message)
+	assert.NoError(t, result.problem)
+}
+
+func TestNewWatchEvent_MarksDryRunRequests(t *testing.T) {
+	t.Parallel()
+	dryRun := true
+	request := &admissionv1.AdmissionRequest{Operation: admissionv1.Create, DryRun: &dryRun}
+
+	event := newWatchEvent(request, WatchedObject{Metadata: Metadata{Name: "kyma"}})
+
+	assert.True(t, event.DryRun)
+}
@@ -0,0 +1,81 @@
+package admissionreview
+
+import (
+	"fmt"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/fieldpath"
+)
+
+// onlyIgnoredFieldsChanged removes the fields ignored for the module from the spec and status of both objects
+// and reports whether they were the only change. Suppressed events are counted per module.
+func (h *Handler) onlyIgnoredFieldsChanged(moduleName string, resource *Resource,
+	oldObject, object *WatchedObject,
+) bool {
+	paths := h.config.ModuleConfig(moduleName).IgnoredFieldPaths()
+	if len(paths) == 0 {
+		return false
+	}
+	stripIgnoredFields(paths, oldObject, object)
+	changed, err := h.checkForChange(resource, *oldObject, *object)
+	if err != nil || changed {
+		return false
+	}
+	h.metrics.UpdateSuppressedEventsTotal(moduleName)
+	return true
+}
+
+// withoutIgnoredWatchedFields removes the fields ignored for the module from both documents and returns
+// the changed watched fields that still differ afterwards. Only the changed fields are compared again,
+// as removing the same fields from both documents keeps equal fields equal. Suppressed events are counted
+// per module.
+func (h *Handler) withoutIgnoredWatchedFields(moduleName string, changedFields []watchedField,
+	oldDocument, newDocument map[string]any,
+) []watchedField {
+	paths := h.config.ModuleConfig(moduleName).IgnoredFieldPaths()
+	if len(paths) == 0 {
+		return changedFields
+	}
+	deleteFields(paths, oldDocument, newDocument)
+	changedPaths := make([]fieldpath.Path, 0, len(changedFields))
+	for _, field := range changedFields {
+		changedPaths = append(changedPaths, field.path)
+	}
+	changedFields = compareWatchedFields(changedPaths, oldDocument, newDocument)
+	if len(changedFields) == 0 {
+		h.metrics.UpdateSuppressedEventsTotal(moduleName)
+	}
+	return changedFields
+}
+
+// stripIgnoredFields removes the ignored fields from the spec and status of the objects.
+// The paths are relative to the object, e.g. .status.lastHeartbeatTime.
+func stripIgnoredFields(paths []fieldpath.Path, objects ...*WatchedObject) {
+	for _, object := range objects {
+		document := map[string]any{"spec": object.Spec, "status": object.Status}
+		deleteFields(paths, document)
+		object.Spec = remainingFields(document, "spec", object.Spec)
+		object.Status = remainingFields(document, "status", object.Status)
+	}
+}
+
+// remainingFields returns the fields left under key after the ignored fields were removed. An ignored
+// spec or status becomes empty instead of missing, as a missing spec is treated as a change.
+func remainingFields(document map[string]any, key string, original map[string]any) map[string]any {
+	fields, found := document[key].(map[string]any)
+	if !found && original != nil {
+		return map[string]any{}
+	}
+	return fields
+}
+
+func deleteFields(paths []fieldpath.Path, documents ...map[string]any) {
+	for _, document := range documents {
+		for _, path := range paths {
+			path.Delete(document)
+		}
+	}
+}
+
+func suppressedMessage(object WatchedObject) string {
+	return fmt.Sprintf("only ignored fields changed on watched resource %s/%s", object.Namespace, object.Name)
+}
@@ -0,0 +1,49 @@
+package admissionreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStripIgnoredFields_RemovesFieldsFromSpecAndStatus(t *testing.T) {
+	t.Parallel()
+	object := WatchedObject{
+		Spec: decodeDocument(t, `{"channel":"regular","lastSync":"t1"}`),
+		Status: decodeDocument(t, `{"state":"Ready","conditions":[
+			{"type":"Ready","lastTransitionTime":"t1"},{"type":"Synced","lastTransitionTime":"t2"}
+		]}`),
+	}
+
+	stripIgnoredFields(parsePaths(t, ".spec.lastSync", ".status.conditions[*].lastTransitionTime"), &object)
+
+	assert.Equal(t, decodeDocument(t, `{"channel":"regular"}`), object.Spec)
+	assert.Equal(t, decodeDocument(t, `{"state":"Ready","conditions":[{"type":"Ready"},{"type":"Synced"}]}`),
+		object.Status)
+}
+
+func TestStripIgnoredFields_KeepsRealChange(t *testing.T) {
+	t.Parallel()
+	oldObject := WatchedObject{Status: decodeDocument(t, `{"state":"Processing","lastHeartbeatTime":"t1"}`)}
+	object := WatchedObject{Status: decodeDocument(t, `{"state":"Ready","lastHeartbeatTime":"t2"}`)}
+
+	stripIgnoredFields(parsePaths(t, ".status.lastHeartbeatTime"), &oldObject, &object)
+
+	assert.Equal(t, map[string]any{"state": "Processing"}, oldObject.Status)
+	assert.Equal(t, map[string]any{"state": "Ready"}, object.Status)
+}
+
+func TestStripIgnoredFields_IgnoredSpecIsNoChange(t *testing.T) {
+	t.Parallel()
+	oldObject := WatchedObject{Spec: decodeDocument(t, `{"channel":"regular"}`)}
+	object := WatchedObject{Spec: decodeDocument(t, `{"channel":"fast"}`)}
+
+	stripIgnoredFields(parsePaths(t, ".spec"), &oldObject, &object)
+
+	assert.Equal(t, map[string]any{}, oldObject.Spec)
+	assert.Equal(t, map[string]any{}, object.Spec)
+	changed, err := (&Handler{}).checkForChange(&Resource{}, oldObject, object)
+	require.NoError(t, err)
+	assert.False(t, changed)
+}
@@ -0,0 +1,197 @@
+package admissionreview
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/sethgrid/pester"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+// kcpRequestAttempts is the number of attempts of a request to KCP if the forwarding queue is disabled.
+// The forwarding queue retries failed deliveries itself, so its requests are sent once.
+const kcpRequestAttempts = 3
+
+// batchContract is the contract version under which the KCP listener accepts a JSON array of WatchEvents.
+const batchContract = "v3"
+
+var errKcpRejectedEvent = errors.New("kcp rejected event")
+
+// kcpStatusError is returned if KCP answers a request with a status code other than 200 OK.
+type kcpStatusError struct {
+	statusCode   int
+	responseBody []byte
+}
+
+func (e *kcpStatusError) Error() string {
+	return fmt.Sprintf("%s: responseBody: %s with StatusCode: %d", kcpReqFailedMsg, e.responseBody, e.statusCode)
+}
+
+func (e *kcpStatusError) Unwrap() error {
+	return errKcpRequest
+}
+
+// sendRequestToKcp delivers the events of a module to its destination. Under the batch contract all events
+// are sent as JSON array in one request, otherwise each event is sent in a request of its own.
+func (h *Handler) sendRequestToKcp(ctx context.Context, route *route, envelopes []kcpevent.Envelope) error {
+	if len(envelopes) == 0 {
+		return nil
+	}
+	if route.destination.Contract == batchContract {
+		return h.sendBatchToKcp(ctx, route, envelopes)
+	}
+
+	for _, envelope := range envelopes {
+		eventIDs := strings.Join(envelope.EventIDs(), ",")
+		_, err := h.postToKcp(ctx, route, envelope.ModuleName, eventIDs, &envelope.Event)
+		if err != nil {
+			return err
+		}
+		h.loggerFrom(ctx).V(1).Info("sent request to KCP successfully for resource "+envelope.Event.Watched.String(),
+			"postBody", envelope.Event, "eventID", eventIDs)
+	}
+	return nil
+}
+
+// sendBatchToKcp sends the events in one request and logs the events rejected by KCP. A batch rejected
+// as too large is split in halves, which are sent one after the other.
+func (h *Handler) sendBatchToKcp(ctx context.Context, route *route, envelopes []kcpevent.Envelope) error {
+	logger := h.loggerFrom(ctx)
+	watcherEvents := make([]listenerTypes.WatchEvent, 0, len(envelopes))
+	eventIDs := make([]string, 0, len(envelopes))
+	for _, envelope := range envelopes {
+		watcherEvents = append(watcherEvents, envelope.Event)
+		eventIDs = append(eventIDs, envelope.EventIDs()...)
+	}
+
+	responseBody, err := h.postToKcp(ctx, route, envelopes[0].ModuleName, strings.Join(eventIDs, ","), watcherEvents)
+	var statusErr *kcpStatusError
+	if errors.As(err, &statusErr) && statusErr.statusCode == http.StatusRequestEntityTooLarge && len(envelopes) > 1 {
+		// listeners limiting batches to the size of a single event accept smaller batches
+		half := len(envelopes) / 2
+		err = h.sendBatchToKcp(ctx, route, envelopes[:half])
+		if err != nil {
+			return err
+		}
+		return h.sendBatchToKcp(ctx, route, envelopes[half:])
+	}
+	if err != nil {
+		return err
+	}
+
+	// the events were received by KCP at this point, so a broken response must not lead to a retry
+	batchResponse := listenerTypes.BatchResponse{}
+	err = json.Unmarshal(responseBody, &batchResponse)
+	if err != nil {
+		logger.Error(err, "failed to parse KCP batch response", "responseBody", string(responseBody))
+		return nil
+	}
+	for _, itemError := range batchResponse.Errors {
+		h.updateFailedKCPTotal(route, watchermetrics.ReasonRejected)
+		if itemError.Index < 0 || itemError.Index >= len(watcherEvents) {
+			logger.Error(errKcpRejectedEvent, itemError.Message, "index", itemError.Index)
+			continue
+		}
+		logger.Error(errKcpRejectedEvent, itemError.Message, "postBody", watcherEvents[itemError.Index],
+			"eventID", eventIDs[itemError.Index])
+	}
+
+	logger.V(1).Info(fmt.Sprintf("sent batch request to KCP successfully, %d of %d events accepted",
+		batchResponse.Accepted, len(watcherEvents)))
+	return nil
+}
+
+// postToKcp sends the payload to the event endpoint of the module at the destination of the route
+// and returns the response body. The IDs of the sent events are passed in the event ID header.
+func (h *Handler) postToKcp(ctx context.Context, route *route, moduleName, eventIDs string,
+	payload any,
+) ([]byte, error) {
+	logger := h.loggerFrom(ctx)
+	if route.required() {
+		h.metrics.UpdateKCPTotal()
+	}
+
+	destination := route.destination
+	if destination.Address == "" || destination.Contract == "" {
+		return nil, h.logAndReturnKCPErr(logger, route, errEmptyConfig, watchermetrics.ReasonKcpAddress)
+	}
+
+	url := fmt.Sprintf("https://%s/%s/%s/%s", destination.Address, destination.Contract, moduleName, eventEndpoint)
+	resilientClient := pester.NewExtendedClient(route.client.HTTPClient())
+	resilientClient.Backoff = pester.ExponentialBackoff
+	resilientClient.MaxRetries = kcpRequestAttempts
+	if h.queue != nil {
+		resilientClient.MaxRetries = 1
+	}
+	resilientClient.KeepLog = true
+
+	postBody, err := json.Marshal(payload)
+	if err != nil {
+		return nil, h.logAndReturnKCPErr(logger, route, err, watchermetrics.ReasonRequest)
+	}
+	request, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewBuffer(postBody))
+	if err != nil {
+		return nil, h.logAndReturnKCPErr(logger, route, err, watchermetrics.ReasonRequest)
+	}
+	request.Header.Set("Content-Type", "application/json")
+	if strings.Trim(eventIDs, ",") != "" {
+		request.Header.Set(listenerTypes.EventIDHeader, eventIDs)
+	}
+	resp, err := resilientClient.Do(request)
+	if err != nil {
+		route.lastFailure.Store(time.Now().UnixNano())
+		err = errors.Join(errKcpRequest, err)
+		logger.Error(err, resilientClient.LogString(), "postBody", payload)
+		h.updateFailedKCPTotal(route, watchermetrics.ReasonResponse)
+		return nil, err
+	}
+	route.lastResponse.Store(time.Now().UnixNano())
+	for range resilientClient.SuccessRetryNum - 1 {
+		h.updateFailedKCPTotal(route, watchermetrics.ReasonResponse)
+	}
+	defer resp.Body.Close()
+	responseBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err = errors.Join(errKcpRequest, err)
+		logger.Error(err, err.Error(), "postBody", payload)
+		h.updateFailedKCPTotal(route, watchermetrics.ReasonResponse)
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		err = &kcpStatusError{statusCode: resp.StatusCode, responseBody: responseBody}
+		logger.Error(err, err.Error(), "postBody", payload)
+		h.updateFailedKCPTotal(route, watchermetrics.ReasonResponse)
+		return nil, err
+	}
+
+	return responseBody, nil
+}
+
+func (h *Handler) logAndReturnKCPErr(logger logr.Logger, route *route, err error,
+	reason watchermetrics.KcpErrReason,
+) error {
+	err = errors.Join(errKcpRequest, err)
+	logger.Error(err, err.Error())
+	h.updateFailedKCPTotal(route, reason)
+	return err
+}
+
+// updateFailedKCPTotal counts failed requests to required destinations. Failures of best-effort destinations
+// are only counted per destination, so they do not affect the alerting on the delivery to KCP.
+func (h *Handler) updateFailedKCPTotal(route *route, reason watchermetrics.KcpErrReason) {
+	if route.required() {
+		h.metrics.UpdateFailedKCPTotal(reason)
+	}
+}
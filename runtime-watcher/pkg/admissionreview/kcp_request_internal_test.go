@@ -0,0 +1,101 @@
+package admissionreview
+
+import (
+	"encoding/json"
+	"net/http"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+)
+
+func newBatchEnvelopes(ids ...string) []kcpevent.Envelope {
+	envelopes := make([]kcpevent.Envelope, 0, len(ids))
+	for _, id := range ids {
+		envelopes = append(envelopes, kcpevent.Envelope{
+			ModuleName: "kyma",
+			ID:         id,
+			Event:      listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: id}},
+		})
+	}
+	return envelopes
+}
+
+func newBatchHandler(t *testing.T, certProvider *tlstest.CertProvider, kcp *kcpServer) *Handler {
+	t.Helper()
+	return newDeliveryHandler(t, certProvider, kcp, func(config *serverconfig.ServerConfig) {
+		config.KCPContract = batchContract
+	})
+}
+
+func writeBatchResponse(t *testing.T, writer http.ResponseWriter, response listenerTypes.BatchResponse) {
+	t.Helper()
+	writer.Header().Set("Content-Type", "application/json")
+	assert.NoError(t, json.NewEncoder(writer).Encode(response))
+}
+
+func TestSendBatchToKcp(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+
+	t.Run("partially accepted batch is delivered", func(t *testing.T) {
+		t.Parallel()
+		kcp := newKCPServerFunc(t, certProvider, func(writer http.ResponseWriter, _ *http.Request) {
+			writeBatchResponse(t, writer, listenerTypes.BatchResponse{
+				Accepted: 2,
+				Errors:   []listenerTypes.BatchItemError{{Index: 1, Message: "watched name must not be empty"}},
+			})
+		})
+		handler := newBatchHandler(t, certProvider, kcp)
+
+		err := handler.sendRequestToKcp(t.Context(), handler.moduleRoutes("kyma")[0], newBatchEnvelopes("a", "b", "c"))
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(1), kcp.requests.Load())
+		assert.Equal(t, "a,b,c", kcp.eventIDs.Load())
+	})
+
+	t.Run("batch too large is split until it is accepted", func(t *testing.T) {
+		t.Parallel()
+		var accepted atomic.Int32
+		kcp := newKCPServerFunc(t, certProvider, func(writer http.ResponseWriter, request *http.Request) {
+			var events []listenerTypes.WatchEvent
+			assert.NoError(t, json.NewDecoder(request.Body).Decode(&events))
+			if len(events) > 1 {
+				writer.WriteHeader(http.StatusRequestEntityTooLarge)
+				return
+			}
+			accepted.Add(1)
+			writeBatchResponse(t, writer, listenerTypes.BatchResponse{Accepted: 1})
+		})
+		handler := newBatchHandler(t, certProvider, kcp)
+
+		err := handler.sendRequestToKcp(t.Context(), handler.moduleRoutes("kyma")[0], newBatchEnvelopes("a", "b", "c"))
+
+		require.NoError(t, err)
+		assert.Equal(t, int32(3), accepted.Load())
+		assert.Equal(t, int32(5), kcp.requests.Load())
+	})
+
+	t.Run("single event too large fails", func(t *testing.T) {
+		t.Parallel()
+		kcp := newKCPServer(t, certProvider, http.StatusRequestEntityTooLarge)
+		handler := newBatchHandler(t, certProvider, kcp)
+
+		err := handler.sendRequestToKcp(t.Context(), handler.moduleRoutes("kyma")[0], newBatchEnvelopes("a"))
+
+		var statusErr *kcpStatusError
+		require.ErrorAs(t, err, &statusErr)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, statusErr.statusCode)
+		assert.Equal(t, int32(1), kcp.requests.Load())
+	})
+}
@@ -0,0 +1,71 @@
+package admissionreview
+
+import (
+	"context"
+	"time"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/ratelimit"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+const (
+	kcpReqThrottledMsg = "kcp request throttled"
+	kcpReqDroppedMsg   = "kcp request dropped by rate limit"
+)
+
+// newRateLimiters creates a Limiter for each module with a rate limit.
+func newRateLimiters(modules map[string]serverconfig.ModuleConfig) map[string]*ratelimit.Limiter {
+	limiters := map[string]*ratelimit.Limiter{}
+	for name, module := range modules {
+		if module.RateLimit.IsEmpty() {
+			continue
+		}
+		limiters[name] = ratelimit.New(
+			ratelimit.Config{
+				EventsPerSecond: module.RateLimit.Module.EventsPerSecond,
+				Burst:           module.RateLimit.Module.Burst,
+			},
+			ratelimit.Config{
+				EventsPerSecond: module.RateLimit.Object.EventsPerSecond,
+				Burst:           module.RateLimit.Object.Burst,
+			},
+			module.RateLimit.MaxDelay.Duration)
+	}
+	return limiters
+}
+
+// dispatchWithinRateLimit dispatches the event unless it exceeds the rate limit of its module. An event
+// exceeding it is delayed until the limit allows it, replacing an already delayed event for the same object,
+// or dropped if it would be delayed for too long. Dry-run events are dropped instead of delayed, as they
+// must not replace a delayed event of a persisted change.
+func (h *Handler) dispatchWithinRateLimit(ctx context.Context, envelope kcpevent.Envelope) admissionResult {
+	limiter, found := h.rateLimiters[envelope.ModuleName]
+	if !found {
+		return h.dispatch(ctx, envelope)
+	}
+	if !envelope.Event.DryRun && h.throttled.Replace(envelope) {
+		h.metrics.UpdateThrottledEventsTotal(envelope.ModuleName, watchermetrics.ThrottleReplaced)
+		return admissionResult{message: kcpReqThrottledMsg, outcome: outcomeThrottled}
+	}
+
+	delay, allowed := limiter.Reserve(envelope.Key(), time.Now())
+	switch {
+	case !allowed || (delay > 0 && envelope.Event.DryRun):
+		h.metrics.UpdateThrottledEventsTotal(envelope.ModuleName, watchermetrics.ThrottleDropped)
+		h.loggerFrom(ctx).V(1).Info("dropped event exceeding rate limit for resource "+envelope.Event.Watched.String(),
+			"module", envelope.ModuleName, "eventID", envelope.ID)
+		return admissionResult{message: kcpReqDroppedMsg, outcome: outcomeDropped}
+	case delay > 0:
+		h.metrics.UpdateThrottledEventsTotal(envelope.ModuleName, watchermetrics.ThrottleDelayed)
+		h.throttled.Add(envelope, delay)
+		return admissionResult{message: kcpReqThrottledMsg, outcome: outcomeThrottled}
+	}
+	return h.dispatch(ctx, envelope)
+}
+
+// forwardThrottled dispatches a delayed event once the rate limit allows it or on shutdown.
+func (h *Handler) forwardThrottled(ctx context.Context, envelope kcpevent.Envelope) {
+	_ = h.dispatch(ctx, envelope)
+}
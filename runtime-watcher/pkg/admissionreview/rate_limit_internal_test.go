@@ -0,0 +1,75 @@
+package admissionreview
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+)
+
+func limitObjectEvents(config *serverconfig.ServerConfig) {
+	config.Modules = map[string]serverconfig.ModuleConfig{"kyma": {RateLimit: serverconfig.RateLimit{
+		Object:   serverconfig.TokenBucket{EventsPerSecond: 20, Burst: 1},
+		MaxDelay: metav1.Duration{Duration: time.Second},
+	}}}
+}
+
+func TestForward_LimitsEventsOfObject(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	request := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800002"}
+	event := listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "kcp-system", Name: "kyma"}}
+	dryRunEvent := event
+	dryRunEvent.DryRun = true
+
+	t.Run("events exceeding limit are delayed and coalesced", func(t *testing.T) {
+		t.Parallel()
+		kcp := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newDeliveryHandler(t, certProvider, kcp, limitObjectEvents)
+
+		assert.Equal(t, outcomeDelivered, handler.forward(t.Context(), request, "kyma", event).outcome)
+		assert.Equal(t, outcomeThrottled, handler.forward(t.Context(), request, "kyma", event).outcome)
+		assert.Equal(t, outcomeThrottled, handler.forward(t.Context(), request, "kyma", event).outcome)
+
+		assert.Eventually(t, func() bool { return kcp.requests.Load() == 2 }, time.Second, 10*time.Millisecond)
+		assert.Never(t, func() bool { return kcp.requests.Load() > 2 }, 100*time.Millisecond, 10*time.Millisecond)
+	})
+
+	t.Run("delayed event carries IDs of replaced events to KCP", func(t *testing.T) {
+		t.Parallel()
+		kcp := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newDeliveryHandler(t, certProvider, kcp, limitObjectEvents)
+		delayed := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800003"}
+		replacing := &admissionv1.AdmissionRequest{UID: "705ab4f5-6393-11e8-b7cc-42010a800004"}
+
+		assert.Equal(t, outcomeDelivered, handler.forward(t.Context(), request, "kyma", event).outcome)
+		assert.Equal(t, outcomeThrottled, handler.forward(t.Context(), delayed, "kyma", event).outcome)
+		assert.Equal(t, outcomeThrottled, handler.forward(t.Context(), replacing, "kyma", event).outcome)
+
+		eventIDs := string(delayed.UID) + "," + string(replacing.UID)
+		assert.Eventually(t, func() bool { return kcp.eventIDs.Load() == eventIDs }, time.Second, 10*time.Millisecond)
+		assert.Equal(t, int32(2), kcp.requests.Load())
+	})
+
+	t.Run("dry-run events exceeding limit are dropped", func(t *testing.T) {
+		t.Parallel()
+		kcp := newKCPServer(t, certProvider, http.StatusOK)
+		handler := newDeliveryHandler(t, certProvider, kcp, limitObjectEvents)
+
+		assert.Equal(t, outcomeDelivered, handler.forward(t.Context(), request, "kyma", dryRunEvent).outcome)
+		assert.Equal(t, outcomeDropped, handler.forward(t.Context(), request, "kyma", dryRunEvent).outcome)
+
+		assert.Equal(t, int32(1), kcp.requests.Load())
+	})
+}
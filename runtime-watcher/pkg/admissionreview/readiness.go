@@ -0,0 +1,38 @@
+package admissionreview
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+var errKcpUnreachable = errors.New("kcp destination unreachable")
+
+// CheckClientCertificates returns an error if a KCP client certificate or CA bundle could not be loaded
+// on its last change or a client certificate is not valid.
+func (h *Handler) CheckClientCertificates() error {
+	errs := make([]error, 0, len(h.kcpClients))
+	for _, client := range h.kcpClients {
+		errs = append(errs, client.Check())
+	}
+	return errors.Join(errs...)
+}
+
+// CheckKCPReachable returns an error if requests to a required destination failed without response
+// and the destination has not responded within the window. Destinations that were not sent any request
+// are considered reachable.
+func (h *Handler) CheckKCPReachable(window time.Duration) error {
+	now := time.Now()
+	errs := make([]error, 0)
+	for _, destination := range h.config.AllDestinations() {
+		route := h.routes[destination]
+		lastResponse := time.Unix(0, route.lastResponse.Load())
+		if !route.required() || route.lastFailure.Load() <= lastResponse.UnixNano() ||
+			now.Sub(lastResponse) <= window {
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%w: %s did not respond since %s", errKcpUnreachable,
+			destination.Address, lastResponse.Format(time.RFC3339)))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,32 @@
+package admissionreview
+
+import (
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+)
+
+func TestCheckKCPReachable(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	handler := newFanOutHandler(t, certProvider, newKCPServer(t, certProvider, http.StatusOK))
+	primary := handler.moduleRoutes("kyma")[0]
+
+	require.NoError(t, handler.CheckKCPReachable(0), "destination without requests is reachable")
+
+	primary.lastFailure.Store(time.Now().UnixNano())
+	require.NoError(t, handler.CheckKCPReachable(time.Hour), "destination responded within the window")
+	require.ErrorIs(t, handler.CheckKCPReachable(0), errKcpUnreachable)
+
+	envelopes := []kcpevent.Envelope{{ModuleName: "kyma"}}
+	require.NoError(t, handler.sendToDestinations(t.Context(), envelopes))
+	assert.NoError(t, handler.CheckKCPReachable(0), "destination responded after the failure")
+}
@@ -0,0 +1,113 @@
+package admissionreview
+
+import (
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	// warningPrefix marks the warnings of the watcher among the warnings returned to the client.
+	warningPrefix = "runtime-watcher: "
+	// maxWarningLength is the length up to which warnings are shown by the API server without being truncated.
+	maxWarningLength = 256
+
+	// audit annotations are prefixed with the name of the webhook by the API server.
+	auditAnnotationError         = "error"
+	auditAnnotationEventID       = "event-id"
+	auditAnnotationModule        = "module"
+	auditAnnotationOutcome       = "outcome"
+	auditAnnotationKcpStatusCode = "kcp-status-code"
+
+	contentTypeHeader = "Content-Type"
+	jsonContentType   = "application/json"
+	allowHeader       = "Allow"
+)
+
+var (
+	errMethodNotAllowed     = errors.New("method not allowed, admission reviews must be sent with POST")
+	errUnsupportedMediaType = errors.New("unsupported content type, admission reviews must be sent as " +
+		jsonContentType)
+)
+
+// deliveryOutcome tells what happened to the event of an admission request.
+type deliveryOutcome string
+
+const (
+	outcomeNotForwarded deliveryOutcome = "not-forwarded"
+	outcomeDelivered    deliveryOutcome = "delivered"
+	outcomeQueued       deliveryOutcome = "queued"
+	outcomeDebounced    deliveryOutcome = "debounced"
+	outcomeThrottled    deliveryOutcome = "throttled"
+	outcomeDropped      deliveryOutcome = "dropped"
+	outcomeSpooled      deliveryOutcome = "spooled"
+	outcomeFailed       deliveryOutcome = "failed"
+)
+
+// admissionResult is the outcome of an admission request. The request is always allowed,
+// a watcher-side problem is reported as warning and audit annotation.
+type admissionResult struct {
+	message string
+	problem error
+
+	module  string
+	eventID string
+	outcome deliveryOutcome
+	// kcpStatusCode is the status code KCP answered a direct delivery with, 0 if there was no answer.
+	kcpStatusCode int
+}
+
+func resultOf(message string) admissionResult {
+	return admissionResult{message: message, outcome: outcomeNotForwarded}
+}
+
+func failedWith(err error) admissionResult {
+	result := admissionResult{message: err.Error(), problem: err, outcome: outcomeFailed}
+	var statusErr *kcpStatusError
+	if errors.As(err, &statusErr) {
+		result.kcpStatusCode = statusErr.statusCode
+	}
+	return result
+}
+
+// auditAnnotations links the admission request in the audit log of SKR to the event forwarded to KCP.
+func (r admissionResult) auditAnnotations() map[string]string {
+	annotations := map[string]string{auditAnnotationOutcome: string(r.outcome)}
+	if r.module != "" {
+		annotations[auditAnnotationModule] = r.module
+	}
+	if r.eventID != "" {
+		annotations[auditAnnotationEventID] = r.eventID
+	}
+	if r.kcpStatusCode != 0 {
+		annotations[auditAnnotationKcpStatusCode] = strconv.Itoa(r.kcpStatusCode)
+	}
+	if r.problem != nil {
+		annotations[auditAnnotationError] = r.problem.Error()
+	}
+	return annotations
+}
+
+// warning returns the problem as single line warning of limited length.
+func (r admissionResult) warning() string {
+	warning := warningPrefix + strings.ReplaceAll(r.problem.Error(), "\n", ": ")
+	if len(warning) > maxWarningLength {
+		warning = warning[:maxWarningLength-3] + "..."
+	}
+	return warning
+}
+
+// validateHTTPRequest checks that the request can contain an admission review and returns the status code
+// to reject it with otherwise.
+func validateHTTPRequest(request *http.Request) (int, error) {
+	if request.Method != http.MethodPost {
+		return http.StatusMethodNotAllowed, errMethodNotAllowed
+	}
+	mediaType, _, err := mime.ParseMediaType(request.Header.Get(contentTypeHeader))
+	if err != nil || mediaType != jsonContentType {
+		return http.StatusUnsupportedMediaType, errUnsupportedMediaType
+	}
+	return http.StatusOK, nil
+}
@@ -0,0 +1,100 @@
+package admissionreview
+
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/circuitbreaker"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpclient"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+// route delivers events to a KCP destination through the client and circuit breaker of the destination.
+type route struct {
+	destination serverconfig.Destination
+	client      *kcpclient.Client
+	breaker     *circuitbreaker.Breaker
+
+	// lastResponse and lastFailure are the times in unix nanoseconds of the last response of the destination
+	// and of the last request that got none.
+	lastResponse atomic.Int64
+	lastFailure  atomic.Int64
+}
+
+// newRoutes creates a route for every configured destination. Destinations with the same certificates
+// share a client, and destinations with the same address share a circuit breaker.
+func (h *Handler) newRoutes() error {
+	clients := map[kcpclient.Config]*kcpclient.Client{}
+	breakers := map[string]*circuitbreaker.Breaker{}
+	h.routes = map[serverconfig.Destination]*route{}
+	for _, destination := range h.config.AllDestinations() {
+		clientConfig := kcpclient.Config{
+			Certificate:    h.clientCertificate(destination),
+			CACertPath:     destination.CACertPath,
+			TLSCertPath:    destination.TLSCertPath,
+			TLSKeyPath:     destination.TLSKeyPath,
+			ReloadInterval: h.config.CertReloadInterval,
+			Timeout:        HTTPTimeout,
+		}
+		client, found := clients[clientConfig]
+		if !found {
+			var err error
+			client, err = kcpclient.New(h.logger.WithName("kcp-client"), clientConfig, &h.metrics)
+			if err != nil {
+				return fmt.Errorf("failed to create KCP client for %s: %w", destination.Address, err)
+			}
+			clients[clientConfig] = client
+			h.kcpClients = append(h.kcpClients, client)
+		}
+
+		breaker, found := breakers[destination.Address]
+		if !found && h.config.CircuitBreakerFailureThreshold > 0 {
+			breaker = circuitbreaker.New(destination.Address, circuitbreaker.Config{
+				FailureThreshold: h.config.CircuitBreakerFailureThreshold,
+				ProbeInterval:    h.config.CircuitBreakerProbeInterval,
+			}, &h.metrics)
+			breakers[destination.Address] = breaker
+		}
+
+		destinationRoute := &route{destination: destination, client: client, breaker: breaker}
+		destinationRoute.lastResponse.Store(time.Now().UnixNano())
+		h.routes[destination] = destinationRoute
+	}
+	return nil
+}
+
+// clientCertificate labels the metrics of the client certificate of a destination.
+// Certificates other than the global one are told apart by their path.
+func (h *Handler) clientCertificate(destination serverconfig.Destination) watchermetrics.Certificate {
+	if destination.TLSCertPath == h.config.TLSCertPath {
+		return watchermetrics.CertificateKCPClient
+	}
+	return watchermetrics.CertificateKCPClient + watchermetrics.Certificate(":"+destination.TLSCertPath)
+}
+
+// moduleRoutes returns the routes to the destinations of the module.
+func (h *Handler) moduleRoutes(moduleName string) []*route {
+	destinations := h.config.Destinations(moduleName)
+	routes := make([]*route, 0, len(destinations))
+	for _, destination := range destinations {
+		routes = append(routes, h.routes[destination])
+	}
+	return routes
+}
+
+// required reports whether a failed delivery to the route fails the delivery of the events.
+func (r *route) required() bool {
+	return r.destination.Mode == serverconfig.DeliveryRequired
+}
+
+// batchSupported reports whether all destinations accept batches of events.
+func (h *Handler) batchSupported() bool {
+	for destination := range h.routes {
+		if destination.Contract != batchContract {
+			return false
+		}
+	}
+	return true
+}
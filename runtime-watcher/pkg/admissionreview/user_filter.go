@@ -0,0 +1,40 @@
+package admissionreview
+
+import (
+	"slices"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+// userFiltered reports whether the change by the user of an admission request is not forwarded because of
+// the user filter of the module. The outcome is counted for modules with a user filter.
+func (h *Handler) userFiltered(moduleName string, user authenticationv1.UserInfo) bool {
+	filter := h.config.ModuleConfig(moduleName).UserFilter
+	if filter.IsEmpty() {
+		return false
+	}
+	outcome := filterUser(filter, user)
+	h.metrics.UpdateUserFilterTotal(moduleName, outcome)
+	return outcome != watchermetrics.UserForwarded
+}
+
+func filterUser(filter serverconfig.UserFilter, user authenticationv1.UserInfo) watchermetrics.UserFilterOutcome {
+	if len(filter.Include) > 0 && !slices.ContainsFunc(filter.Include, userMatcher(user)) {
+		return watchermetrics.UserNotIncluded
+	}
+	if slices.ContainsFunc(filter.Exclude, userMatcher(user)) {
+		return watchermetrics.UserExcluded
+	}
+	return watchermetrics.UserForwarded
+}
+
+func userMatcher(user authenticationv1.UserInfo) func(serverconfig.UserRule) bool {
+	return func(rule serverconfig.UserRule) bool {
+		return (rule.Username == "" || rule.Username == user.Username) &&
+			(rule.Group == "" || slices.Contains(user.Groups, rule.Group)) &&
+			(rule.ServiceAccount == "" || rule.ServiceAccountUsername() == user.Username)
+	}
+}
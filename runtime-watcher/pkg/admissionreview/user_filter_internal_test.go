@@ -0,0 +1,71 @@
+package admissionreview
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	authenticationv1 "k8s.io/api/authentication/v1"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+func TestFilterUser(t *testing.T) {
+	t.Parallel()
+	lifecycleManager := authenticationv1.UserInfo{
+		Username: "system:serviceaccount:kcp-system:lifecycle-manager",
+		Groups:   []string{"system:serviceaccounts", "system:serviceaccounts:kcp-system"},
+	}
+	admin := authenticationv1.UserInfo{Username: "kubernetes-admin", Groups: []string{"system:masters"}}
+	excludeLifecycleManager := serverconfig.UserFilter{
+		Exclude: []serverconfig.UserRule{{ServiceAccount: "kcp-system/lifecycle-manager"}},
+	}
+	tests := []struct {
+		name     string
+		filter   serverconfig.UserFilter
+		user     authenticationv1.UserInfo
+		expected watchermetrics.UserFilterOutcome
+	}{
+		{
+			name:     "excluded service account",
+			filter:   excludeLifecycleManager,
+			user:     lifecycleManager,
+			expected: watchermetrics.UserExcluded,
+		},
+		{
+			name:     "user not matching exclude rule",
+			filter:   excludeLifecycleManager,
+			user:     admin,
+			expected: watchermetrics.UserForwarded,
+		},
+		{
+			name:     "user not matching include rule",
+			filter:   serverconfig.UserFilter{Include: []serverconfig.UserRule{{Group: "system:masters"}}},
+			user:     lifecycleManager,
+			expected: watchermetrics.UserNotIncluded,
+		},
+		{
+			name: "included user matching exclude rule",
+			filter: serverconfig.UserFilter{
+				Include: []serverconfig.UserRule{{Group: "system:masters"}},
+				Exclude: []serverconfig.UserRule{{Username: "kubernetes-admin"}},
+			},
+			user:     admin,
+			expected: watchermetrics.UserExcluded,
+		},
+		{
+			name: "rule requires all fields to match",
+			filter: serverconfig.UserFilter{
+				Exclude: []serverconfig.UserRule{{Username: "kubernetes-admin", Group: "system:serviceaccounts"}},
+			},
+			user:     admin,
+			expected: watchermetrics.UserForwarded,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, testCase.expected, filterUser(testCase.filter, testCase.user))
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package admissionreview
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+
+	admissionv1 "k8s.io/api/admission/v1"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/fieldpath"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/jsondiff"
+)
+
+// watchedField is a field that differs between the old and the new object of an UPDATE.
+type watchedField struct {
+	path     fieldpath.Path
+	oldValue any
+	newValue any
+	inOld    bool
+	inNew    bool
+}
+
+// validateWatchedFields forwards an UPDATE if one of the fields configured for the module changed.
+func (h *Handler) validateWatchedFields(ctx context.Context, request *admissionv1.AdmissionRequest,
+	moduleName string, paths []fieldpath.Path, oldObject, object WatchedObject,
+) admissionResult {
+	oldDocument, newDocument := map[string]any{}, map[string]any{}
+	err := errors.Join(json.Unmarshal(request.OldObject.Raw, &oldDocument),
+		json.Unmarshal(request.Object.Raw, &newDocument))
+	if err != nil {
+		h.loggerFrom(ctx).Error(errors.Join(errAdmission, err), "failed to unmarshal admission review resource object")
+	}
+
+	changedFields := compareWatchedFields(paths, oldDocument, newDocument)
+	if len(changedFields) == 0 {
+		return resultOf(noChangeMessage(object))
+	}
+	changedFields = h.withoutIgnoredWatchedFields(moduleName, changedFields, oldDocument, newDocument)
+	if len(changedFields) == 0 {
+		return resultOf(suppressedMessage(object))
+	}
+
+	event := newWatchEvent(request, object)
+	h.summarizeChange(ctx, moduleName, func() ([]jsondiff.Operation, error) {
+		return diffWatchedFields(changedFields)
+	}, &event)
+	return h.forward(ctx, request, moduleName, event)
+}
+
+func compareWatchedFields(paths []fieldpath.Path, oldDocument, newDocument map[string]any) []watchedField {
+	changedFields := make([]watchedField, 0)
+	for _, path := range paths {
+		field := watchedField{path: path}
+		field.oldValue, field.inOld = path.Get(oldDocument)
+		field.newValue, field.inNew = path.Get(newDocument)
+		if field.inOld != field.inNew || !reflect.DeepEqual(field.oldValue, field.newValue) {
+			changedFields = append(changedFields, field)
+		}
+	}
+	return changedFields
+}
+
+// diffWatchedFields computes the JSON patch of the changed fields. Each field is wrapped in its parent,
+// so a field that only exists in one of the objects results in an add or remove operation.
+func diffWatchedFields(changedFields []watchedField) ([]jsondiff.Operation, error) {
+	operations := make([]jsondiff.Operation, 0, len(changedFields))
+	for _, field := range changedFields {
+		parent, key := field.path.Parent()
+		oldParent, newParent := map[string]any{}, map[string]any{}
+		if field.inOld {
+			oldParent[key] = field.oldValue
+		}
+		if field.inNew {
+			newParent[key] = field.newValue
+		}
+		fieldOperations, err := jsondiff.Diff(parent, oldParent, newParent)
+		if err != nil {
+			return nil, err
+		}
+		operations = append(operations, fieldOperations...)
+	}
+	return operations, nil
+}
+
+func noChangeMessage(object WatchedObject) string {
+	return fmt.Sprintf("no change detected on watched resource %s/%s", object.Namespace, object.Name)
+}
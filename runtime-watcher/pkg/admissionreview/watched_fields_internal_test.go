@@ -0,0 +1,58 @@
+package admissionreview
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/fieldpath"
+)
+
+func parsePaths(t *testing.T, selectors ...string) []fieldpath.Path {
+	t.Helper()
+	paths := make([]fieldpath.Path, 0, len(selectors))
+	for _, selector := range selectors {
+		path, err := fieldpath.Parse(selector)
+		require.NoError(t, err)
+		paths = append(paths, path)
+	}
+	return paths
+}
+
+func decodeDocument(t *testing.T, document string) map[string]any {
+	t.Helper()
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(document), &decoded))
+	return decoded
+}
+
+func TestCompareWatchedFields_ReturnsOnlyChangedFields(t *testing.T) {
+	t.Parallel()
+	oldDocument := decodeDocument(t, `{"metadata":{"labels":{"a":"1"}},"spec":{"x":1},"data":{"k":"v"}}`)
+	newDocument := decodeDocument(t, `{"metadata":{"labels":{"a":"2"}},"spec":{"x":2},"data":{"k":"v"}}`)
+
+	changedFields := compareWatchedFields(parsePaths(t, ".metadata.labels", ".data"), oldDocument, newDocument)
+
+	require.Len(t, changedFields, 1)
+	assert.Equal(t, ".metadata.labels", changedFields[0].path.String())
+}
+
+func TestDiffWatchedFields_AddsAndRemovesFields(t *testing.T) {
+	t.Parallel()
+	oldDocument := decodeDocument(t, `{"metadata":{"annotations":{"owner":"kcp"}}}`)
+	newDocument := decodeDocument(t, `{"metadata":{"annotations":{}},"data":{"k":"v"}}`)
+	changedFields := compareWatchedFields(parsePaths(t, `.metadata.annotations["owner"]`, ".data"),
+		oldDocument, newDocument)
+
+	operations, err := diffWatchedFields(changedFields)
+
+	require.NoError(t, err)
+	patch, err := json.Marshal(operations)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[
+		{"op":"remove","path":"/metadata/annotations/owner"},
+		{"op":"add","path":"/data","value":{"k":"v"}}
+	]`, string(patch))
+}
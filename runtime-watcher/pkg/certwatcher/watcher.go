@@ -0,0 +1,146 @@
+package certwatcher
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var (
+	errCertificateNotYetValid = errors.New("certificate is not yet valid")
+	errCertificateExpired     = errors.New("certificate has expired")
+)
+
+// LoadFunc loads the watched files. If it fails, the files are loaded again on the next poll.
+type LoadFunc func() error
+
+type Config struct {
+	// Certificate identifies the watched files in metrics.
+	Certificate watchermetrics.Certificate
+	// Paths are the files loaded together, e.g. certificate, key and CA bundle.
+	Paths []string
+	// Interval is the time between two checks of the files for changes.
+	Interval time.Duration
+}
+
+// Watcher polls certificate files and loads them again when their content changes.
+//
+// Mounted secrets are updated by swapping a symlink to a new directory, so the content
+// of the files is compared instead of relying on file events or modification times.
+type Watcher struct {
+	logger  logr.Logger
+	config  Config
+	load    LoadFunc
+	metrics *watchermetrics.WatcherMetrics
+
+	mu       sync.Mutex
+	checksum [sha256.Size]byte
+	err      error
+}
+
+func New(logger logr.Logger, config Config, load LoadFunc, metrics *watchermetrics.WatcherMetrics) *Watcher {
+	return &Watcher{
+		logger:  logger,
+		config:  config,
+		load:    load,
+		metrics: metrics,
+	}
+}
+
+// Load loads the files regardless of whether they changed.
+func (w *Watcher) Load() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	checksum, err := w.sum()
+	if err != nil {
+		w.metrics.UpdateCertificateReloadsTotal(w.config.Certificate, watchermetrics.ReloadFailed)
+		w.err = err
+		return err
+	}
+	return w.loadWith(checksum)
+}
+
+// Err returns the error of the last load, nil if it succeeded. The previously loaded files stay in use
+// while it fails.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.err
+}
+
+// CheckValidity returns an error if the certificate is not valid at the given time.
+func CheckValidity(certificate *x509.Certificate, now time.Time) error {
+	if now.Before(certificate.NotBefore) {
+		return fmt.Errorf("%w before %s", errCertificateNotYetValid, certificate.NotBefore.Format(time.RFC3339))
+	}
+	if now.After(certificate.NotAfter) {
+		return fmt.Errorf("%w at %s", errCertificateExpired, certificate.NotAfter.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// Run polls the files until ctx is done.
+func (w *Watcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.Interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		err := w.reloadIfChanged()
+		if err != nil {
+			w.logger.Error(err, "failed to reload certificate", "paths", w.config.Paths)
+		}
+	}
+}
+
+func (w *Watcher) reloadIfChanged() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	checksum, err := w.sum()
+	if err != nil {
+		w.metrics.UpdateCertificateReloadsTotal(w.config.Certificate, watchermetrics.ReloadFailed)
+		w.err = err
+		return err
+	}
+	if checksum == w.checksum {
+		return nil
+	}
+	w.logger.Info("certificate files changed, reloading", "paths", w.config.Paths)
+	return w.loadWith(checksum)
+}
+
+func (w *Watcher) loadWith(checksum [sha256.Size]byte) error {
+	w.err = w.load()
+	if w.err != nil {
+		w.metrics.UpdateCertificateReloadsTotal(w.config.Certificate, watchermetrics.ReloadFailed)
+		return w.err
+	}
+	w.metrics.UpdateCertificateReloadsTotal(w.config.Certificate, watchermetrics.ReloadSucceeded)
+	// the checksum is taken before loading, so a change during the load is picked up by the next poll
+	w.checksum = checksum
+	return nil
+}
+
+func (w *Watcher) sum() ([sha256.Size]byte, error) {
+	hash := sha256.New()
+	for _, path := range w.config.Paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return [sha256.Size]byte{}, fmt.Errorf("could not read certificate file: %w", err)
+		}
+		hash.Write(content)
+	}
+	return [sha256.Size]byte(hash.Sum(nil)), nil
+}
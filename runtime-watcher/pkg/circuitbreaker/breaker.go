@@ -0,0 +1,116 @@
+package circuitbreaker
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+type Config struct {
+	// FailureThreshold is the number of consecutive failures after which the breaker opens.
+	FailureThreshold int
+	// ProbeInterval is the time the breaker stays open before a single probe request is let through.
+	ProbeInterval time.Duration
+}
+
+// Breaker stops requests to a destination after repeated failures.
+//
+// It starts closed and lets all requests through. After FailureThreshold consecutive failures it opens and
+// rejects all requests. Once ProbeInterval has passed it turns half-open and lets one probe request through:
+// a successful probe closes the breaker, a failed one opens it again.
+type Breaker struct {
+	destination string
+	config      Config
+	metrics     *watchermetrics.WatcherMetrics
+
+	mu       sync.Mutex
+	state    watchermetrics.BreakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func New(destination string, config Config, metrics *watchermetrics.WatcherMetrics) *Breaker {
+	breaker := &Breaker{
+		destination: destination,
+		config:      config,
+		metrics:     metrics,
+		state:       watchermetrics.BreakerClosed,
+	}
+	metrics.UpdateBreakerState(destination, breaker.state)
+	return breaker
+}
+
+// Allow returns ErrOpen if a request must not be sent. Every allowed request must be
+// followed by a call to Done with its result.
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case watchermetrics.BreakerClosed:
+		return nil
+	case watchermetrics.BreakerOpen:
+		if time.Since(b.openedAt) < b.config.ProbeInterval {
+			b.metrics.UpdateBreakerRejectedTotal(b.destination)
+			return ErrOpen
+		}
+		b.transition(watchermetrics.BreakerHalfOpen)
+		b.probing = true
+		return nil
+	case watchermetrics.BreakerHalfOpen:
+		if b.probing {
+			b.metrics.UpdateBreakerRejectedTotal(b.destination)
+			return ErrOpen
+		}
+		b.probing = true
+		return nil
+	}
+	return nil
+}
+
+// Done records the result of an allowed request.
+func (b *Breaker) Done(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if err == nil {
+		b.failures = 0
+		if b.state != watchermetrics.BreakerClosed {
+			b.transition(watchermetrics.BreakerClosed)
+		}
+		return
+	}
+
+	b.failures++
+	if b.state == watchermetrics.BreakerHalfOpen ||
+		b.state == watchermetrics.BreakerClosed && b.failures >= b.config.FailureThreshold {
+		b.openedAt = time.Now()
+		b.transition(watchermetrics.BreakerOpen)
+	}
+}
+
+// Release gives back an allowed request without a result, e.g. because it was cancelled by the caller.
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+// State returns the current state of the breaker.
+func (b *Breaker) State() watchermetrics.BreakerState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+func (b *Breaker) transition(to watchermetrics.BreakerState) {
+	b.metrics.UpdateBreakerTransitionsTotal(b.destination, b.state, to)
+	b.state = to
+	b.metrics.UpdateBreakerState(b.destination, to)
+}
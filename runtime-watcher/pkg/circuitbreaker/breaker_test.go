@@ -0,0 +1,96 @@
+package circuitbreaker_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/circuitbreaker"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var errRequest = errors.New("request failed")
+
+func newBreaker(probeInterval time.Duration) *circuitbreaker.Breaker {
+	return circuitbreaker.New("kcp.example.com", circuitbreaker.Config{
+		FailureThreshold: 2,
+		ProbeInterval:    probeInterval,
+	}, watchermetrics.NewMetrics())
+}
+
+func fail(t *testing.T, breaker *circuitbreaker.Breaker) {
+	t.Helper()
+	require.NoError(t, breaker.Allow())
+	breaker.Done(errRequest)
+}
+
+func TestBreaker_OpensAfterConsecutiveFailures(t *testing.T) {
+	t.Parallel()
+	breaker := newBreaker(time.Hour)
+
+	fail(t, breaker)
+	assert.Equal(t, watchermetrics.BreakerClosed, breaker.State())
+	fail(t, breaker)
+
+	assert.Equal(t, watchermetrics.BreakerOpen, breaker.State())
+	require.ErrorIs(t, breaker.Allow(), circuitbreaker.ErrOpen)
+}
+
+func TestBreaker_SuccessResetsFailureCount(t *testing.T) {
+	t.Parallel()
+	breaker := newBreaker(time.Hour)
+
+	fail(t, breaker)
+	require.NoError(t, breaker.Allow())
+	breaker.Done(nil)
+	fail(t, breaker)
+
+	assert.Equal(t, watchermetrics.BreakerClosed, breaker.State())
+}
+
+func TestBreaker_LetsSingleProbeThroughAfterProbeInterval(t *testing.T) {
+	t.Parallel()
+	breaker := newBreaker(10 * time.Millisecond)
+	fail(t, breaker)
+	fail(t, breaker)
+
+	time.Sleep(20 * time.Millisecond)
+
+	require.NoError(t, breaker.Allow())
+	assert.Equal(t, watchermetrics.BreakerHalfOpen, breaker.State())
+	require.ErrorIs(t, breaker.Allow(), circuitbreaker.ErrOpen)
+
+	breaker.Done(nil)
+	assert.Equal(t, watchermetrics.BreakerClosed, breaker.State())
+	require.NoError(t, breaker.Allow())
+}
+
+func TestBreaker_ReopensAfterFailedProbe(t *testing.T) {
+	t.Parallel()
+	breaker := newBreaker(10 * time.Millisecond)
+	fail(t, breaker)
+	fail(t, breaker)
+
+	time.Sleep(20 * time.Millisecond)
+	fail(t, breaker)
+
+	assert.Equal(t, watchermetrics.BreakerOpen, breaker.State())
+	require.ErrorIs(t, breaker.Allow(), circuitbreaker.ErrOpen)
+}
+
+func TestBreaker_ReleasedProbeLetsNextRequestProbe(t *testing.T) {
+	t.Parallel()
+	breaker := newBreaker(10 * time.Millisecond)
+	fail(t, breaker)
+	fail(t, breaker)
+
+	time.Sleep(20 * time.Millisecond)
+	require.NoError(t, breaker.Allow())
+	breaker.Release()
+
+	assert.Equal(t, watchermetrics.BreakerHalfOpen, breaker.State())
+	require.NoError(t, breaker.Allow())
+}
@@ -0,0 +1,110 @@
+package eventcoalescer
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+// ForwardFunc hands over a coalesced event for delivery within ctx.
+type ForwardFunc func(ctx context.Context, envelope kcpevent.Envelope)
+
+// Coalescer merges repeated events for the same object into one delivery per debounce window.
+type Coalescer struct {
+	forward ForwardFunc
+	metrics *watchermetrics.WatcherMetrics
+
+	mu      sync.Mutex
+	pending map[string]*pendingEvent
+	// releases tracks the events forwarded after their window elapsed, so that Flush can wait for them.
+	releases       sync.WaitGroup
+	releaseCtx     context.Context //nolint:containedctx // cancels released events once Flush expires
+	cancelReleases context.CancelFunc
+}
+
+type pendingEvent struct {
+	envelope kcpevent.Envelope
+	timer    *time.Timer
+}
+
+func New(forward ForwardFunc, metrics *watchermetrics.WatcherMetrics) *Coalescer {
+	releaseCtx, cancelReleases := context.WithCancel(context.Background())
+	return &Coalescer{
+		forward:        forward,
+		metrics:        metrics,
+		pending:        map[string]*pendingEvent{},
+		releaseCtx:     releaseCtx,
+		cancelReleases: cancelReleases,
+	}
+}
+
+// Add schedules the envelope for delivery once the window has elapsed.
+// If an event for the same object is already pending, it is replaced by the envelope, which carries
+// the IDs of the replaced events, and delivered at the end of the already running window.
+func (c *Coalescer) Add(envelope kcpevent.Envelope, window time.Duration) {
+	key := envelope.Key()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if event, found := c.pending[key]; found {
+		event.envelope = envelope.Replacing(event.envelope)
+		c.metrics.UpdateCoalescedEventsTotal(envelope.ModuleName)
+		return
+	}
+	event := &pendingEvent{envelope: envelope}
+	event.timer = time.AfterFunc(window, func() { c.release(key, event) })
+	c.pending[key] = event
+}
+
+// Replace replaces the pending event for the same object by the envelope and reports whether there was one.
+// The envelope is delivered at the end of the already running window. Unlike Add, it leaves counting
+// the replacement to the caller.
+func (c *Coalescer) Replace(envelope kcpevent.Envelope) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	event, found := c.pending[envelope.Key()]
+	if found {
+		event.envelope = envelope.Replacing(event.envelope)
+	}
+	return found
+}
+
+// Flush delivers all pending events immediately within ctx and waits until the events released
+// at the end of their window are forwarded. Once ctx expires, the forwarding of released events is cancelled.
+func (c *Coalescer) Flush(ctx context.Context) {
+	stop := context.AfterFunc(ctx, c.cancelReleases)
+	defer stop()
+
+	c.mu.Lock()
+	events := make([]kcpevent.Envelope, 0, len(c.pending))
+	for key, event := range c.pending {
+		// an event whose timer already fired is taken over from its release, which is still waiting for the lock
+		event.timer.Stop()
+		events = append(events, event.envelope)
+		delete(c.pending, key)
+	}
+	c.mu.Unlock()
+
+	for _, envelope := range events {
+		c.forward(ctx, envelope)
+	}
+	c.releases.Wait()
+}
+
+func (c *Coalescer) release(key string, event *pendingEvent) {
+	c.mu.Lock()
+	found := c.pending[key] == event
+	if found {
+		delete(c.pending, key)
+		c.releases.Add(1)
+	}
+	c.mu.Unlock()
+
+	if found {
+		defer c.releases.Done()
+		c.forward(c.releaseCtx, event.envelope)
+	}
+}
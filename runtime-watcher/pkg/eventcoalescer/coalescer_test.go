@@ -0,0 +1,143 @@
+package eventcoalescer_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/eventcoalescer"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+type forwardRecorder struct {
+	mu        sync.Mutex
+	envelopes []kcpevent.Envelope
+}
+
+func (r *forwardRecorder) forward(_ context.Context, envelope kcpevent.Envelope) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.envelopes = append(r.envelopes, envelope)
+}
+
+func (r *forwardRecorder) forwarded() []kcpevent.Envelope {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]kcpevent.Envelope{}, r.envelopes...)
+}
+
+func newEnvelope(name, kind string) kcpevent.Envelope {
+	return kcpevent.Envelope{
+		ModuleName: "kyma",
+		Event: listenerTypes.WatchEvent{
+			Watched:    listenerTypes.ObjectKey{Namespace: "default", Name: name},
+			WatchedGvk: metav1.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: kind},
+		},
+	}
+}
+
+func TestCoalescer_MergesEventsForSameObjectWithinWindow(t *testing.T) {
+	t.Parallel()
+	recorder := &forwardRecorder{}
+	coalescer := eventcoalescer.New(recorder.forward, watchermetrics.NewMetrics())
+
+	for range 5 {
+		coalescer.Add(newEnvelope("a", "Kyma"), 20*time.Millisecond)
+	}
+	coalescer.Add(newEnvelope("a", "Manifest"), 20*time.Millisecond)
+
+	require.Eventually(t, func() bool { return len(recorder.forwarded()) == 2 },
+		time.Second, 5*time.Millisecond)
+	assert.Never(t, func() bool { return len(recorder.forwarded()) > 2 },
+		50*time.Millisecond, 5*time.Millisecond)
+}
+
+func TestCoalescer_FlushDeliversPendingEventsImmediately(t *testing.T) {
+	t.Parallel()
+	recorder := &forwardRecorder{}
+	coalescer := eventcoalescer.New(recorder.forward, watchermetrics.NewMetrics())
+
+	coalescer.Add(newEnvelope("a", "Kyma"), time.Hour)
+	coalescer.Add(newEnvelope("b", "Kyma"), time.Hour)
+	coalescer.Flush(t.Context())
+
+	assert.Len(t, recorder.forwarded(), 2)
+}
+
+func TestCoalescer_ReplacesOnlyPendingEvents(t *testing.T) {
+	t.Parallel()
+	recorder := &forwardRecorder{}
+	coalescer := eventcoalescer.New(recorder.forward, watchermetrics.NewMetrics())
+	pending := newEnvelope("a", "Kyma")
+	pending.ID = "705ab4f5-6393-11e8-b7cc-42010a800001"
+	replacement := newEnvelope("a", "Kyma")
+	replacement.ID = "705ab4f5-6393-11e8-b7cc-42010a800002"
+
+	assert.False(t, coalescer.Replace(pending))
+	coalescer.Add(pending, time.Hour)
+	assert.True(t, coalescer.Replace(replacement))
+	coalescer.Flush(t.Context())
+
+	forwarded := recorder.forwarded()
+	require.Len(t, forwarded, 1)
+	assert.Equal(t, replacement.ID, forwarded[0].ID)
+	assert.Equal(t, []string{pending.ID, replacement.ID}, forwarded[0].EventIDs())
+}
+
+func TestCoalescer_FlushWaitsForReleasedEvents(t *testing.T) {
+	t.Parallel()
+	released := make(chan struct{})
+	unblock := make(chan struct{})
+	recorder := &forwardRecorder{}
+	coalescer := eventcoalescer.New(func(ctx context.Context, envelope kcpevent.Envelope) {
+		close(released)
+		<-unblock
+		recorder.forward(ctx, envelope)
+	}, watchermetrics.NewMetrics())
+	coalescer.Add(newEnvelope("a", "Kyma"), time.Millisecond)
+	<-released
+
+	flushed := make(chan struct{})
+	go func() {
+		coalescer.Flush(t.Context())
+		close(flushed)
+	}()
+
+	assert.Never(t, func() bool {
+		select {
+		case <-flushed:
+			return true
+		default:
+			return false
+		}
+	}, 50*time.Millisecond, 5*time.Millisecond)
+	close(unblock)
+	<-flushed
+	assert.Len(t, recorder.forwarded(), 1)
+}
+
+func TestCoalescer_FlushCancelsReleasedEventsOnceContextExpires(t *testing.T) {
+	t.Parallel()
+	released := make(chan struct{})
+	coalescer := eventcoalescer.New(func(ctx context.Context, _ kcpevent.Envelope) {
+		close(released)
+		<-ctx.Done()
+	}, watchermetrics.NewMetrics())
+	coalescer.Add(newEnvelope("a", "Kyma"), time.Millisecond)
+	<-released
+	ctx, cancel := context.WithTimeout(t.Context(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	coalescer.Flush(ctx)
+
+	assert.Less(t, time.Since(start), time.Second)
+}
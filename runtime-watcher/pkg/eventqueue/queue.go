@@ -0,0 +1,209 @@
+package eventqueue
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var (
+	ErrQueueFull   = errors.New("forwarding queue is full")
+	ErrQueueClosed = errors.New("forwarding queue is shut down")
+	errDrain       = errors.New("forwarding queue was not drained")
+)
+
+type Config struct {
+	// Size is the maximum number of events waiting for delivery.
+	Size int
+	// Workers is the number of goroutines delivering events concurrently.
+	Workers int
+	// MaxRetries is the number of additional delivery attempts after a failed one.
+	MaxRetries int
+	// RetryBackoff is the wait time before the first retry, it doubles with every further retry.
+	RetryBackoff time.Duration
+	// BatchSize is the maximum number of queued events a worker delivers at once.
+	BatchSize int
+}
+
+// FailureFunc receives the events that could not be delivered after all retries or before shutdown.
+type FailureFunc func(envelopes []kcpevent.Envelope)
+
+// Queue is a bounded in-memory queue of events drained by a pool of workers.
+type Queue struct {
+	logger  logr.Logger
+	config  Config
+	deliver kcpevent.DeliverFunc
+	failed  FailureFunc
+	metrics *watchermetrics.WatcherMetrics
+
+	items   chan kcpevent.Envelope
+	mu      sync.RWMutex
+	closed  bool
+	workers sync.WaitGroup
+
+	deliveryCtx    context.Context //nolint:containedctx // cancels in-flight deliveries on shutdown
+	stopDeliveries context.CancelFunc
+}
+
+// New creates a Queue delivering events with deliver.
+// failed is optional and called with the events that are dropped after all retries or on shutdown.
+func New(logger logr.Logger, config Config, deliver kcpevent.DeliverFunc, failed FailureFunc,
+	metrics *watchermetrics.WatcherMetrics,
+) *Queue {
+	deliveryCtx, stopDeliveries := context.WithCancel(context.Background())
+	return &Queue{
+		logger:         logger,
+		config:         config,
+		deliver:        deliver,
+		failed:         failed,
+		metrics:        metrics,
+		items:          make(chan kcpevent.Envelope, config.Size),
+		deliveryCtx:    deliveryCtx,
+		stopDeliveries: stopDeliveries,
+	}
+}
+
+// Start launches the configured number of workers.
+func (q *Queue) Start() {
+	for range q.config.Workers {
+		q.workers.Go(q.work)
+	}
+}
+
+// Enqueue adds the envelope to the queue without blocking.
+// It returns ErrQueueFull if there is no capacity left and ErrQueueClosed after Shutdown was called.
+func (q *Queue) Enqueue(envelope kcpevent.Envelope) error {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+	if q.closed {
+		q.metrics.UpdateQueueDroppedEventsTotal(watchermetrics.DropReasonShutdown)
+		return ErrQueueClosed
+	}
+
+	select {
+	case q.items <- envelope:
+		q.metrics.UpdateQueueDepth(len(q.items))
+		return nil
+	default:
+		q.metrics.UpdateQueueDroppedEventsTotal(watchermetrics.DropReasonQueueFull)
+		return ErrQueueFull
+	}
+}
+
+// Shutdown stops accepting new events and waits until all queued events are delivered.
+// If ctx expires first, in-flight deliveries are cancelled and the remaining events are passed to
+// the failure callback.
+func (q *Queue) Shutdown(ctx context.Context) error {
+	q.mu.Lock()
+	if !q.closed {
+		q.closed = true
+		close(q.items)
+	}
+	q.mu.Unlock()
+
+	drained := make(chan struct{})
+	go func() {
+		q.workers.Wait()
+		close(drained)
+	}()
+
+	select {
+	case <-drained:
+		q.stopDeliveries()
+		return nil
+	case <-ctx.Done():
+		q.stopDeliveries()
+		<-drained
+		return fmt.Errorf("%w: %w", errDrain, ctx.Err())
+	}
+}
+
+func (q *Queue) work() {
+	for envelope := range q.items {
+		batch := q.collectBatch(envelope)
+		q.metrics.UpdateQueueDepth(len(q.items))
+		for _, moduleBatch := range groupByModule(batch) {
+			if q.deliveryCtx.Err() != nil {
+				q.drop(moduleBatch, watchermetrics.DropReasonShutdown)
+				continue
+			}
+			err := q.deliverWithRetry(moduleBatch)
+			if err == nil {
+				continue
+			}
+			if q.deliveryCtx.Err() != nil {
+				q.drop(moduleBatch, watchermetrics.DropReasonShutdown)
+				continue
+			}
+			q.logger.Error(err, "dropping events after failed delivery attempts",
+				"module", moduleBatch[0].ModuleName, "events", len(moduleBatch))
+			q.drop(moduleBatch, watchermetrics.DropReasonRetriesExhausted)
+		}
+	}
+}
+
+// drop counts the events that leave the queue undelivered and passes them to the failure callback.
+func (q *Queue) drop(envelopes []kcpevent.Envelope, reason watchermetrics.DropReason) {
+	for range envelopes {
+		q.metrics.UpdateQueueDroppedEventsTotal(reason)
+	}
+	if q.failed != nil {
+		q.failed(envelopes)
+	}
+}
+
+// collectBatch adds already queued events to the first one, up to the configured batch size.
+func (q *Queue) collectBatch(first kcpevent.Envelope) []kcpevent.Envelope {
+	batch := []kcpevent.Envelope{first}
+	for len(batch) < q.config.BatchSize {
+		select {
+		case envelope, ok := <-q.items:
+			if !ok {
+				return batch
+			}
+			batch = append(batch, envelope)
+		default:
+			return batch
+		}
+	}
+	return batch
+}
+
+// groupByModule splits the batch into batches of the same module, preserving the order of events.
+func groupByModule(batch []kcpevent.Envelope) [][]kcpevent.Envelope {
+	groups := make([][]kcpevent.Envelope, 0, 1)
+	indexByModule := map[string]int{}
+	for _, envelope := range batch {
+		idx, found := indexByModule[envelope.ModuleName]
+		if !found {
+			idx = len(groups)
+			indexByModule[envelope.ModuleName] = idx
+			groups = append(groups, nil)
+		}
+		groups[idx] = append(groups[idx], envelope)
+	}
+	return groups
+}
+
+func (q *Queue) deliverWithRetry(envelopes []kcpevent.Envelope) error {
+	backoff := q.config.RetryBackoff
+	err := q.deliver(q.deliveryCtx, envelopes)
+	for attempt := 0; err != nil && attempt < q.config.MaxRetries; attempt++ {
+		select {
+		case <-q.deliveryCtx.Done():
+			return errors.Join(err, q.deliveryCtx.Err())
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		q.metrics.UpdateQueueRetriesTotal()
+		err = q.deliver(q.deliveryCtx, envelopes)
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+package eventqueue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/eventqueue"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var errDelivery = errors.New("delivery failed")
+
+func newEnvelope(name string) kcpevent.Envelope {
+	return kcpevent.Envelope{
+		ModuleName: "kyma",
+		Event:      listenerTypes.WatchEvent{Watched: listenerTypes.ObjectKey{Namespace: "default", Name: name}},
+	}
+}
+
+func newQueue(config eventqueue.Config, deliver kcpevent.DeliverFunc) *eventqueue.Queue {
+	return eventqueue.New(logr.Discard(), config, deliver, nil, watchermetrics.NewMetrics())
+}
+
+func TestQueue_DeliversAllEventsBeforeShutdownReturns(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	delivered := make([]string, 0)
+	queue := newQueue(eventqueue.Config{Size: 10, Workers: 2},
+		func(_ context.Context, envelopes []kcpevent.Envelope) error {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, e := range envelopes {
+				delivered = append(delivered, e.Event.Watched.Name)
+			}
+			return nil
+		})
+	queue.Start()
+
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, queue.Enqueue(newEnvelope(name)))
+	}
+	require.NoError(t, queue.Shutdown(t.Context()))
+
+	assert.ElementsMatch(t, []string{"a", "b", "c"}, delivered)
+}
+
+func TestQueue_DeliversQueuedEventsInBatchesPerModule(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	batches := make([][]string, 0)
+	queue := newQueue(eventqueue.Config{Size: 10, Workers: 1, BatchSize: 3},
+		func(_ context.Context, envelopes []kcpevent.Envelope) error {
+			mu.Lock()
+			defer mu.Unlock()
+			batch := make([]string, 0, len(envelopes))
+			for _, e := range envelopes {
+				batch = append(batch, e.ModuleName+"/"+e.Event.Watched.Name)
+			}
+			batches = append(batches, batch)
+			return nil
+		})
+
+	first, second := newEnvelope("a"), newEnvelope("b")
+	second.ModuleName = "other"
+	for _, envelope := range []kcpevent.Envelope{first, second, newEnvelope("c"), newEnvelope("d")} {
+		require.NoError(t, queue.Enqueue(envelope))
+	}
+	queue.Start()
+	require.NoError(t, queue.Shutdown(t.Context()))
+
+	assert.Equal(t, [][]string{{"kyma/a", "kyma/c"}, {"other/b"}, {"kyma/d"}}, batches)
+}
+
+func TestQueue_EnqueueReturnsErrorWhenFull(t *testing.T) {
+	t.Parallel()
+	queue := newQueue(eventqueue.Config{Size: 1, Workers: 1}, func(context.Context, []kcpevent.Envelope) error {
+		return nil
+	})
+
+	require.NoError(t, queue.Enqueue(newEnvelope("a")))
+	require.ErrorIs(t, queue.Enqueue(newEnvelope("b")), eventqueue.ErrQueueFull)
+}
+
+func TestQueue_EnqueueReturnsErrorAfterShutdown(t *testing.T) {
+	t.Parallel()
+	queue := newQueue(eventqueue.Config{Size: 1, Workers: 1}, func(context.Context, []kcpevent.Envelope) error {
+		return nil
+	})
+	queue.Start()
+	require.NoError(t, queue.Shutdown(t.Context()))
+
+	require.ErrorIs(t, queue.Enqueue(newEnvelope("a")), eventqueue.ErrQueueClosed)
+}
+
+func TestQueue_RetriesFailedDelivery(t *testing.T) {
+	t.Parallel()
+	var attempts atomic.Int32
+	queue := newQueue(eventqueue.Config{Size: 1, Workers: 1, MaxRetries: 2, RetryBackoff: time.Millisecond},
+		func(context.Context, []kcpevent.Envelope) error {
+			if attempts.Add(1) < 3 {
+				return errDelivery
+			}
+			return nil
+		})
+	queue.Start()
+
+	require.NoError(t, queue.Enqueue(newEnvelope("a")))
+	require.NoError(t, queue.Shutdown(t.Context()))
+
+	assert.Equal(t, int32(3), attempts.Load())
+}
+
+func TestQueue_ShutdownCancelsPendingDeliveriesWhenContextExpires(t *testing.T) {
+	t.Parallel()
+	queue := newQueue(eventqueue.Config{Size: 1, Workers: 1}, func(ctx context.Context, _ []kcpevent.Envelope) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	queue.Start()
+	require.NoError(t, queue.Enqueue(newEnvelope("a")))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+}
+
+func TestQueue_ShutdownPassesUndeliveredEventsToFailureCallback(t *testing.T) {
+	t.Parallel()
+	var mu sync.Mutex
+	var failed []string
+	queue := eventqueue.New(logr.Discard(), eventqueue.Config{Size: 2, Workers: 1, BatchSize: 1},
+		func(ctx context.Context, _ []kcpevent.Envelope) error {
+			<-ctx.Done()
+			return ctx.Err()
+		}, func(envelopes []kcpevent.Envelope) {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, envelope := range envelopes {
+				failed = append(failed, envelope.Event.Watched.Name)
+			}
+		}, watchermetrics.NewMetrics())
+	queue.Start()
+	require.NoError(t, queue.Enqueue(newEnvelope("a")))
+	require.NoError(t, queue.Enqueue(newEnvelope("b")))
+
+	ctx, cancel := context.WithTimeout(t.Context(), 10*time.Millisecond)
+	defer cancel()
+
+	require.ErrorIs(t, queue.Shutdown(ctx), context.DeadlineExceeded)
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Equal(t, []string{"a", "b"}, failed)
+}
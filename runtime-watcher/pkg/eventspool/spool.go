@@ -0,0 +1,339 @@
+package eventspool
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+const (
+	spoolFileName  = "events.log"
+	dirPermission  = 0o700
+	filePermission = 0o600
+	// compactionFactor triggers a rewrite of the log once it is this many times larger than the live records.
+	compactionFactor = 2
+)
+
+var errRecordTooLarge = errors.New("event exceeds the spool size limit")
+
+type Config struct {
+	// Dir is the directory holding the write-ahead log, e.g. an emptyDir volume.
+	Dir string
+	// MaxBytes limits the size of the spooled events, the oldest events are dropped first.
+	MaxBytes int64
+	// MaxAge is the duration after which spooled events are dropped.
+	MaxAge time.Duration
+}
+
+// Spool is a file-backed write-ahead log of events that could not be delivered to KCP.
+// Only the latest event per object is kept, and events are replayed in the order they were spooled.
+type Spool struct {
+	logger  logr.Logger
+	config  Config
+	metrics *watchermetrics.WatcherMetrics
+
+	mu        sync.Mutex
+	records   []record
+	liveBytes int64
+	file      *os.File
+	fileBytes int64
+
+	replayMu sync.Mutex
+}
+
+type record struct {
+	Key         string                   `json:"key"`
+	ModuleName  string                   `json:"module"`
+	EventID     string                   `json:"eventId,omitempty"`
+	ReplacedIDs []string                 `json:"replacedEventIds,omitempty"`
+	Event       listenerTypes.WatchEvent `json:"event"`
+	SpooledAt   time.Time                `json:"spooledAt"`
+
+	size int64
+}
+
+// Open loads the events spooled by a previous run from the configured directory.
+func Open(logger logr.Logger, config Config, metrics *watchermetrics.WatcherMetrics) (*Spool, error) {
+	err := os.MkdirAll(config.Dir, dirPermission)
+	if err != nil {
+		return nil, fmt.Errorf("could not create spool directory: %w", err)
+	}
+
+	spool := &Spool{
+		logger:  logger,
+		config:  config,
+		metrics: metrics,
+	}
+	err = spool.load()
+	if err != nil {
+		return nil, err
+	}
+
+	spool.mu.Lock()
+	defer spool.mu.Unlock()
+	spool.enforceLimits()
+	err = spool.rewrite()
+	if err != nil {
+		return nil, err
+	}
+	spool.updateMetrics()
+	return spool, nil
+}
+
+// Append adds the envelope to the spool, replacing a spooled event for the same object.
+func (s *Spool) Append(envelope kcpevent.Envelope) error {
+	rec := record{
+		Key:         envelope.Key(),
+		ModuleName:  envelope.ModuleName,
+		EventID:     envelope.ID,
+		ReplacedIDs: envelope.ReplacedIDs,
+		Event:       envelope.Event,
+		SpooledAt:   time.Now(),
+	}
+	line, err := encode(&rec)
+	if err != nil {
+		return err
+	}
+	if rec.size > s.config.MaxBytes {
+		s.metrics.UpdateSpoolDroppedEventsTotal(watchermetrics.DropReasonSpoolFull)
+		return errRecordTooLarge
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, err = s.file.Write(line)
+	if err != nil {
+		return fmt.Errorf("could not write to spool: %w", err)
+	}
+	err = s.file.Sync()
+	if err != nil {
+		return fmt.Errorf("could not sync spool: %w", err)
+	}
+	s.fileBytes += rec.size
+
+	s.remove(rec.Key)
+	s.records = append(s.records, rec)
+	s.liveBytes += rec.size
+	s.enforceLimits()
+	s.updateMetrics()
+
+	if s.fileBytes > compactionFactor*s.liveBytes {
+		return s.rewrite()
+	}
+	return nil
+}
+
+// Len returns the number of spooled events.
+func (s *Spool) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.records)
+}
+
+// Replay delivers the spooled events one by one in the order they were spooled.
+// It stops at the first failed delivery and keeps the remaining events for the next replay.
+func (s *Spool) Replay(ctx context.Context, deliver kcpevent.DeliverFunc) error {
+	s.replayMu.Lock()
+	defer s.replayMu.Unlock()
+
+	var deliveryErr error
+	for {
+		rec, found := s.oldest()
+		if !found {
+			break
+		}
+		envelope := kcpevent.Envelope{
+			ModuleName:  rec.ModuleName,
+			ID:          rec.EventID,
+			ReplacedIDs: rec.ReplacedIDs,
+			Event:       rec.Event,
+		}
+		deliveryErr = deliver(ctx, []kcpevent.Envelope{envelope})
+		if deliveryErr != nil {
+			break
+		}
+		s.mu.Lock()
+		// the event may have been replaced by a newer one for the same object during delivery
+		if idx := s.index(rec.Key); idx >= 0 && s.records[idx].SpooledAt.Equal(rec.SpooledAt) {
+			s.remove(rec.Key)
+		}
+		s.updateMetrics()
+		s.mu.Unlock()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.rewrite()
+	if deliveryErr != nil {
+		return errors.Join(fmt.Errorf("replay of spooled events stopped: %w", deliveryErr), err)
+	}
+	return err
+}
+
+// UpdateMetrics refreshes the depth and age metrics of the spool.
+func (s *Spool) UpdateMetrics() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.updateMetrics()
+}
+
+// Close closes the underlying file.
+func (s *Spool) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.file.Close()
+	if err != nil {
+		return fmt.Errorf("could not close spool: %w", err)
+	}
+	return nil
+}
+
+func (s *Spool) oldest() (record, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.enforceLimits()
+	if len(s.records) == 0 {
+		return record{}, false
+	}
+	return s.records[0], true
+}
+
+func (s *Spool) path() string {
+	return filepath.Join(s.config.Dir, spoolFileName)
+}
+
+// load reads the write-ahead log, a later record for the same object replaces an earlier one.
+// A truncated last line, e.g. from a crash during a write, is skipped.
+func (s *Spool) load() error {
+	content, err := os.ReadFile(s.path())
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("could not read spool: %w", err)
+	}
+
+	scanner := bufio.NewScanner(bytes.NewReader(content))
+	scanner.Buffer(nil, len(content)+1)
+	for scanner.Scan() {
+		rec := record{}
+		err = json.Unmarshal(scanner.Bytes(), &rec)
+		if err != nil {
+			s.logger.Error(err, "skipping corrupted spool record")
+			continue
+		}
+		rec.size = int64(len(scanner.Bytes()) + 1)
+		s.remove(rec.Key)
+		s.records = append(s.records, rec)
+		s.liveBytes += rec.size
+	}
+	if err = scanner.Err(); err != nil {
+		return fmt.Errorf("could not read spool: %w", err)
+	}
+	return nil
+}
+
+// rewrite replaces the write-ahead log with the live records.
+func (s *Spool) rewrite() error {
+	tmpPath := s.path() + ".tmp"
+	tmpFile, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_TRUNC|os.O_WRONLY, filePermission)
+	if err != nil {
+		return fmt.Errorf("could not compact spool: %w", err)
+	}
+	writer := bufio.NewWriter(tmpFile)
+	for idx := range s.records {
+		line, err := encode(&s.records[idx])
+		if err != nil {
+			tmpFile.Close()
+			return err
+		}
+		_, err = writer.Write(line)
+		if err != nil {
+			tmpFile.Close()
+			return fmt.Errorf("could not compact spool: %w", err)
+		}
+	}
+	err = errors.Join(writer.Flush(), tmpFile.Sync(), tmpFile.Close())
+	if err != nil {
+		return fmt.Errorf("could not compact spool: %w", err)
+	}
+	err = os.Rename(tmpPath, s.path())
+	if err != nil {
+		return fmt.Errorf("could not compact spool: %w", err)
+	}
+
+	if s.file != nil {
+		_ = s.file.Close()
+	}
+	s.file, err = os.OpenFile(s.path(), os.O_APPEND|os.O_WRONLY, filePermission)
+	if err != nil {
+		return fmt.Errorf("could not open spool: %w", err)
+	}
+	s.fileBytes = s.liveBytes
+	return nil
+}
+
+// enforceLimits drops expired events and the oldest events exceeding the size limit.
+func (s *Spool) enforceLimits() {
+	expiry := time.Now().Add(-s.config.MaxAge)
+	for len(s.records) > 0 && s.records[0].SpooledAt.Before(expiry) {
+		s.dropOldest(watchermetrics.DropReasonExpired)
+	}
+	for len(s.records) > 0 && s.liveBytes > s.config.MaxBytes {
+		s.dropOldest(watchermetrics.DropReasonSpoolFull)
+	}
+}
+
+func (s *Spool) dropOldest(reason watchermetrics.DropReason) {
+	s.logger.Info("dropping spooled event", "reason", reason, "module", s.records[0].ModuleName,
+		"watched", s.records[0].Event.Watched.String())
+	s.liveBytes -= s.records[0].size
+	s.records = s.records[1:]
+	s.metrics.UpdateSpoolDroppedEventsTotal(reason)
+}
+
+func (s *Spool) index(key string) int {
+	return slices.IndexFunc(s.records, func(rec record) bool { return rec.Key == key })
+}
+
+func (s *Spool) remove(key string) {
+	idx := s.index(key)
+	if idx < 0 {
+		return
+	}
+	s.liveBytes -= s.records[idx].size
+	s.records = slices.Delete(s.records, idx, idx+1)
+}
+
+func (s *Spool) updateMetrics() {
+	var oldestAge time.Duration
+	if len(s.records) > 0 {
+		oldestAge = time.Now().Sub(s.records[0].SpooledAt)
+	}
+	s.metrics.UpdateSpool(len(s.records), oldestAge)
+}
+
+func encode(rec *record) ([]byte, error) {
+	line, err := json.Marshal(rec)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode spool record: %w", err)
+	}
+	line = append(line, '\n')
+	rec.size = int64(len(line))
+	return line, nil
+}
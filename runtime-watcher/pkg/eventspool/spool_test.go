@@ -0,0 +1,143 @@
+package eventspool_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/eventspool"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpevent"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var errDelivery = errors.New("delivery failed")
+
+func newEnvelope(name string) kcpevent.Envelope {
+	return kcpevent.Envelope{
+		ModuleName: "kyma",
+		Event: listenerTypes.WatchEvent{
+			Watched:    listenerTypes.ObjectKey{Namespace: "default", Name: name},
+			WatchedGvk: metav1.GroupVersionKind{Group: "operator.kyma-project.io", Version: "v1beta2", Kind: "Kyma"},
+		},
+	}
+}
+
+func openSpool(t *testing.T, config eventspool.Config) *eventspool.Spool {
+	t.Helper()
+	spool, err := eventspool.Open(logr.Discard(), config, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = spool.Close() })
+	return spool
+}
+
+func replayedNames(t *testing.T, spool *eventspool.Spool) []string {
+	t.Helper()
+	names := make([]string, 0)
+	err := spool.Replay(t.Context(), func(_ context.Context, envelopes []kcpevent.Envelope) error {
+		for _, envelope := range envelopes {
+			names = append(names, envelope.Event.Watched.Name)
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	return names
+}
+
+func TestSpool_ReplaysEventsInOrderAfterRestart(t *testing.T) {
+	t.Parallel()
+	config := eventspool.Config{Dir: t.TempDir(), MaxBytes: 1024 * 1024, MaxAge: time.Hour}
+	spool, err := eventspool.Open(logr.Discard(), config, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, spool.Append(newEnvelope(name)))
+	}
+	require.NoError(t, spool.Close())
+
+	restarted := openSpool(t, config)
+
+	assert.Equal(t, []string{"a", "b", "c"}, replayedNames(t, restarted))
+	assert.Zero(t, restarted.Len())
+}
+
+func TestSpool_KeepsEventIDAfterRestart(t *testing.T) {
+	t.Parallel()
+	config := eventspool.Config{Dir: t.TempDir(), MaxBytes: 1024 * 1024, MaxAge: time.Hour}
+	spool, err := eventspool.Open(logr.Discard(), config, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	envelope := newEnvelope("a")
+	envelope.ID = "705ab4f5-6393-11e8-b7cc-42010a800002"
+	envelope.ReplacedIDs = []string{"705ab4f5-6393-11e8-b7cc-42010a800001"}
+	require.NoError(t, spool.Append(envelope))
+	require.NoError(t, spool.Close())
+
+	restarted := openSpool(t, config)
+
+	var replayed []kcpevent.Envelope
+	err = restarted.Replay(t.Context(), func(_ context.Context, envelopes []kcpevent.Envelope) error {
+		replayed = append(replayed, envelopes...)
+		return nil
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []kcpevent.Envelope{envelope}, replayed)
+}
+
+func TestSpool_KeepsOnlyLatestEventPerObject(t *testing.T) {
+	t.Parallel()
+	config := eventspool.Config{Dir: t.TempDir(), MaxBytes: 1024 * 1024, MaxAge: time.Hour}
+	spool := openSpool(t, config)
+
+	for _, name := range []string{"a", "b", "a", "c", "a"} {
+		require.NoError(t, spool.Append(newEnvelope(name)))
+	}
+
+	assert.Equal(t, 3, spool.Len())
+	assert.Equal(t, []string{"b", "c", "a"}, replayedNames(t, spool))
+}
+
+func TestSpool_StopsReplayAtFirstFailure(t *testing.T) {
+	t.Parallel()
+	spool := openSpool(t, eventspool.Config{Dir: t.TempDir(), MaxBytes: 1024 * 1024, MaxAge: time.Hour})
+	for _, name := range []string{"a", "b", "c"} {
+		require.NoError(t, spool.Append(newEnvelope(name)))
+	}
+
+	err := spool.Replay(t.Context(), func(_ context.Context, envelopes []kcpevent.Envelope) error {
+		if envelopes[0].Event.Watched.Name == "b" {
+			return errDelivery
+		}
+		return nil
+	})
+
+	require.ErrorIs(t, err, errDelivery)
+	assert.Equal(t, []string{"b", "c"}, replayedNames(t, spool))
+}
+
+func TestSpool_DropsOldestEventsExceedingSizeLimit(t *testing.T) {
+	t.Parallel()
+	// a single record is roughly 250 bytes
+	spool := openSpool(t, eventspool.Config{Dir: t.TempDir(), MaxBytes: 600, MaxAge: time.Hour})
+
+	for _, name := range []string{"a", "b", "c", "d"} {
+		require.NoError(t, spool.Append(newEnvelope(name)))
+	}
+
+	assert.Equal(t, []string{"c", "d"}, replayedNames(t, spool))
+}
+
+func TestSpool_DropsExpiredEvents(t *testing.T) {
+	t.Parallel()
+	spool := openSpool(t, eventspool.Config{Dir: t.TempDir(), MaxBytes: 1024 * 1024, MaxAge: 10 * time.Millisecond})
+	require.NoError(t, spool.Append(newEnvelope("a")))
+
+	time.Sleep(20 * time.Millisecond)
+
+	assert.Empty(t, replayedNames(t, spool))
+}
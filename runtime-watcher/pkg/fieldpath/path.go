@@ -0,0 +1,175 @@
+package fieldpath
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/jsondiff"
+)
+
+const wildcard = "[*]"
+
+var (
+	errEmptySelector    = errors.New("selector must not be empty")
+	errInvalidSelector  = errors.New("invalid selector")
+	errUnexpectedChar   = errors.New("expected . or [")
+	errEmptyKey         = errors.New("empty key")
+	errUnquotedKey      = errors.New("bracket key must be quoted")
+	errUnterminatedKey  = errors.New("unterminated bracket key")
+	errTrailingWildcard = errors.New("wildcard must be followed by a key")
+)
+
+// Path selects a field of an object by its keys, e.g. .metadata.annotations["example.com/owner"].
+type Path struct {
+	selector string
+	segments []segment
+}
+
+// segment is a key of an object, or a wildcard matching all items of an array or all values of an object.
+type segment struct {
+	key      string
+	wildcard bool
+}
+
+// Parse parses a JSONPath-like selector. Keys are separated by dots and keys containing dots or other
+// special characters are written in brackets with double or single quotes. [*] matches all items
+// of an array or all values of an object, e.g.
+//
+//	.status.conditions
+//	.status.conditions[*].lastTransitionTime
+//	.metadata.labels["operator.kyma-project.io/managed-by"]
+func Parse(selector string) (Path, error) {
+	if selector == "" {
+		return Path{}, errEmptySelector
+	}
+	segments := make([]segment, 0)
+	rest := selector
+	for rest != "" {
+		var next segment
+		var err error
+		switch {
+		case strings.HasPrefix(rest, wildcard):
+			next, rest = segment{wildcard: true}, rest[len(wildcard):]
+		case rest[0] == '.':
+			next.key, rest = parseDotKey(rest[1:])
+		case rest[0] == '[':
+			next.key, rest, err = parseBracketKey(rest[1:])
+		default:
+			err = errUnexpectedChar
+		}
+		if err == nil && !next.wildcard && next.key == "" {
+			err = errEmptyKey
+		}
+		if err != nil {
+			return Path{}, fmt.Errorf("%w %s: %w", errInvalidSelector, selector, err)
+		}
+		segments = append(segments, next)
+	}
+	if segments[len(segments)-1].wildcard {
+		return Path{}, fmt.Errorf("%w %s: %w", errInvalidSelector, selector, errTrailingWildcard)
+	}
+	return Path{selector: selector, segments: segments}, nil
+}
+
+func parseDotKey(rest string) (string, string) {
+	end := strings.IndexAny(rest, ".[")
+	if end < 0 {
+		return rest, ""
+	}
+	return rest[:end], rest[end:]
+}
+
+func parseBracketKey(rest string) (string, string, error) {
+	if rest == "" || (rest[0] != '"' && rest[0] != '\'') {
+		return "", "", errUnquotedKey
+	}
+	quote := rest[0]
+	end := strings.IndexByte(rest[1:], quote)
+	if end < 0 || !strings.HasPrefix(rest[end+2:], "]") {
+		return "", "", errUnterminatedKey
+	}
+	return rest[1 : end+1], rest[end+3:], nil
+}
+
+// String returns the selector the Path was parsed from.
+func (p Path) String() string {
+	return p.selector
+}
+
+// HasWildcard reports whether the Path selects more than one field.
+func (p Path) HasWildcard() bool {
+	for _, seg := range p.segments {
+		if seg.wildcard {
+			return true
+		}
+	}
+	return false
+}
+
+// Pointer returns the path as JSON pointer, e.g. /metadata/labels.
+func (p Path) Pointer() string {
+	var pointer string
+	for _, seg := range p.segments {
+		pointer = jsondiff.JoinPath(pointer, seg.key)
+	}
+	return pointer
+}
+
+// Parent returns the JSON pointer of the parent field and the key of the selected field in it.
+func (p Path) Parent() (string, string) {
+	var pointer string
+	for _, seg := range p.segments[:len(p.segments)-1] {
+		pointer = jsondiff.JoinPath(pointer, seg.key)
+	}
+	return pointer, p.segments[len(p.segments)-1].key
+}
+
+// Get returns the selected field of a document decoded by encoding/json and whether it exists.
+// A Path with wildcard never selects a field.
+func (p Path) Get(document map[string]any) (any, bool) {
+	var current any = document
+	for _, seg := range p.segments {
+		object, isObject := current.(map[string]any)
+		if !isObject || seg.wildcard {
+			return nil, false
+		}
+		value, found := object[seg.key]
+		if !found {
+			return nil, false
+		}
+		current = value
+	}
+	return current, true
+}
+
+// Delete removes the selected fields from a document decoded by encoding/json.
+func (p Path) Delete(document map[string]any) {
+	deleteSegments(document, p.segments)
+}
+
+func deleteSegments(value any, segments []segment) {
+	switch typed := value.(type) {
+	case map[string]any:
+		if segments[0].wildcard {
+			for _, item := range typed {
+				deleteSegments(item, segments[1:])
+			}
+			return
+		}
+		if len(segments) == 1 {
+			delete(typed, segments[0].key)
+			return
+		}
+		if item, found := typed[segments[0].key]; found {
+			deleteSegments(item, segments[1:])
+		}
+	case []any:
+		if !segments[0].wildcard {
+			return
+		}
+		for _, item := range typed {
+			deleteSegments(item, segments[1:])
+		}
+	}
+}
@@ -0,0 +1,108 @@
+package fieldpath_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/fieldpath"
+)
+
+const document = `{
+	"metadata": {
+		"labels": {"operator.kyma-project.io/managed-by": "lifecycle-manager"},
+		"annotations": {"example.com/owner": "kcp"}
+	},
+	"status": {"conditions": [{"type": "Ready"}]},
+	"data": {"key": "value"}
+}`
+
+func TestPath_Get(t *testing.T) {
+	t.Parallel()
+	decoded := map[string]any{}
+	require.NoError(t, json.Unmarshal([]byte(document), &decoded))
+
+	tests := []struct {
+		selector string
+		pointer  string
+		expected any
+		found    bool
+	}{
+		{".data", "/data", map[string]any{"key": "value"}, true},
+		{".status.conditions", "/status/conditions", []any{map[string]any{"type": "Ready"}}, true},
+		{
+			`.metadata.labels["operator.kyma-project.io/managed-by"]`,
+			"/metadata/labels/operator.kyma-project.io~1managed-by", "lifecycle-manager", true,
+		},
+		{`.metadata.annotations['example.com/owner']`, "/metadata/annotations/example.com~1owner", "kcp", true},
+		{".metadata.annotations.missing", "/metadata/annotations/missing", nil, false},
+		{".data.key.nested", "/data/key/nested", nil, false},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.selector, func(t *testing.T) {
+			t.Parallel()
+			path, err := fieldpath.Parse(testCase.selector)
+			require.NoError(t, err)
+
+			value, found := path.Get(decoded)
+
+			assert.Equal(t, testCase.found, found)
+			assert.Equal(t, testCase.expected, value)
+			assert.Equal(t, testCase.pointer, path.Pointer())
+			assert.Equal(t, testCase.selector, path.String())
+		})
+	}
+}
+
+func TestParse_InvalidSelectors(t *testing.T) {
+	t.Parallel()
+	for _, selector := range []string{
+		"", "metadata", ".metadata.", ".metadata[labels]", `.metadata["labels`, ".a..b", ".status.conditions[*]", ".status[*]x",
+	} {
+		_, err := fieldpath.Parse(selector)
+		assert.Error(t, err, selector)
+	}
+}
+
+func TestPath_Delete(t *testing.T) {
+	t.Parallel()
+	const source = `{"data":{"key":"value"},"status":{"conditions":[{"type":"Ready","at":"t1"}]}}`
+	tests := []struct {
+		selector string
+		expected string
+	}{
+		{".data.key", `{"data":{},"status":{"conditions":[{"type":"Ready","at":"t1"}]}}`},
+		{".data.missing.key", source},
+		{".data[*].nested", source},
+		{".status.conditions[*].at", `{"data":{"key":"value"},"status":{"conditions":[{"type":"Ready"}]}}`},
+		{".status[*][*].type", `{"data":{"key":"value"},"status":{"conditions":[{"at":"t1"}]}}`},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.selector, func(t *testing.T) {
+			t.Parallel()
+			decoded := map[string]any{}
+			require.NoError(t, json.Unmarshal([]byte(source), &decoded))
+			path, err := fieldpath.Parse(testCase.selector)
+			require.NoError(t, err)
+
+			path.Delete(decoded)
+
+			actual, err := json.Marshal(decoded)
+			require.NoError(t, err)
+			assert.JSONEq(t, testCase.expected, string(actual))
+		})
+	}
+}
+
+func TestPath_HasWildcard(t *testing.T) {
+	t.Parallel()
+	path, err := fieldpath.Parse(".status.conditions[*].lastTransitionTime")
+	require.NoError(t, err)
+	assert.True(t, path.HasWildcard())
+
+	path, err = fieldpath.Parse(".status.conditions")
+	require.NoError(t, err)
+	assert.False(t, path.HasWildcard())
+}
@@ -0,0 +1,69 @@
+package health
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-logr/logr"
+)
+
+// Check returns an error describing why the watcher is not ready, nil if it is.
+type Check func() error
+
+// Probes serves the liveness and readiness endpoints of the watcher.
+type Probes struct {
+	logger logr.Logger
+	names  []string
+	checks map[string]Check
+}
+
+func NewProbes(logger logr.Logger) *Probes {
+	return &Probes{
+		logger: logger,
+		checks: map[string]Check{},
+	}
+}
+
+// AddReadinessCheck adds a named check that must pass for the watcher to be ready.
+func (p *Probes) AddReadinessCheck(name string, check Check) {
+	p.names = append(p.names, name)
+	p.checks[name] = check
+}
+
+// Healthz reports that the process serves requests.
+func (p *Probes) Healthz(writer http.ResponseWriter, _ *http.Request) {
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = writer.Write([]byte("ok"))
+}
+
+// Readyz runs all readiness checks and answers with 503 Service Unavailable if one fails.
+// The body lists the outcome of each check, e.g.
+//
+//	[+]serving-cert ok
+//	[-]kcp-client-cert failed: certificate has expired at 2024-01-01T00:00:00Z
+//	readyz check failed
+func (p *Probes) Readyz(writer http.ResponseWriter, _ *http.Request) {
+	var body strings.Builder
+	ready := true
+	for _, name := range p.names {
+		err := p.checks[name]()
+		if err != nil {
+			ready = false
+			p.logger.Error(err, "readiness check failed", "check", name)
+			fmt.Fprintf(&body, "[-]%s failed: %s\n", name, strings.ReplaceAll(err.Error(), "\n", "; "))
+			continue
+		}
+		fmt.Fprintf(&body, "[+]%s ok\n", name)
+	}
+
+	writer.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	writer.Header().Set("X-Content-Type-Options", "nosniff")
+	if !ready {
+		writer.WriteHeader(http.StatusServiceUnavailable)
+		body.WriteString("readyz check failed\n")
+	} else {
+		body.WriteString("readyz check passed\n")
+	}
+	_, _ = writer.Write([]byte(body.String()))
+}
@@ -0,0 +1,56 @@
+package health_test
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/health"
+)
+
+var errExpired = errors.New("certificate has expired")
+
+func probe(t *testing.T, handler http.HandlerFunc) *httptest.ResponseRecorder {
+	t.Helper()
+	recorder := httptest.NewRecorder()
+	handler(recorder, httptest.NewRequestWithContext(t.Context(), http.MethodGet, "/", nil))
+	return recorder
+}
+
+func TestProbes_ReadyzListsOutcomeOfEachCheck(t *testing.T) {
+	t.Parallel()
+	probes := health.NewProbes(logr.Discard())
+	probes.AddReadinessCheck("serving-cert", func() error { return nil })
+	probes.AddReadinessCheck("kcp-client-cert", func() error { return errExpired })
+
+	recorder := probe(t, probes.Readyz)
+
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, "[+]serving-cert ok\n[-]kcp-client-cert failed: certificate has expired\nreadyz check failed\n",
+		recorder.Body.String())
+}
+
+func TestProbes_ReadyzPassesIfAllChecksPass(t *testing.T) {
+	t.Parallel()
+	probes := health.NewProbes(logr.Discard())
+	probes.AddReadinessCheck("serving-cert", func() error { return nil })
+
+	recorder := probe(t, probes.Readyz)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "[+]serving-cert ok\nreadyz check passed\n", recorder.Body.String())
+}
+
+func TestProbes_HealthzIsAlwaysOK(t *testing.T) {
+	t.Parallel()
+	probes := health.NewProbes(logr.Discard())
+	probes.AddReadinessCheck("kcp-client-cert", func() error { return errExpired })
+
+	recorder := probe(t, probes.Healthz)
+
+	assert.Equal(t, http.StatusOK, recorder.Code)
+}
@@ -0,0 +1,106 @@
+package jsondiff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+const (
+	OpAdd     = "add"
+	OpRemove  = "remove"
+	OpReplace = "replace"
+)
+
+// Operation is a single operation of a JSON patch as defined in RFC 6902.
+type Operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// Diff returns the JSON patch turning oldValue into newValue, which are documents decoded by encoding/json.
+// Paths are JSON pointers below prefix, e.g. "/spec". Objects are compared key by key,
+// while arrays that differ are replaced as a whole. The operations are sorted by path.
+func Diff(prefix string, oldValue, newValue any) ([]Operation, error) {
+	operations := make([]Operation, 0)
+	err := diff(prefix, oldValue, newValue, &operations)
+	if err != nil {
+		return nil, err
+	}
+	return operations, nil
+}
+
+// Paths returns the paths changed by the operations.
+func Paths(operations []Operation) []string {
+	paths := make([]string, 0, len(operations))
+	for _, operation := range operations {
+		paths = append(paths, operation.Path)
+	}
+	return paths
+}
+
+// JoinPath appends the escaped key to the JSON pointer.
+func JoinPath(pointer, key string) string {
+	key = strings.ReplaceAll(key, "~", "~0")
+	key = strings.ReplaceAll(key, "/", "~1")
+	return pointer + "/" + key
+}
+
+func diff(path string, oldValue, newValue any, operations *[]Operation) error {
+	oldObject, oldIsObject := oldValue.(map[string]any)
+	newObject, newIsObject := newValue.(map[string]any)
+	if oldIsObject && newIsObject {
+		return diffObjects(path, oldObject, newObject, operations)
+	}
+	if reflect.DeepEqual(oldValue, newValue) {
+		return nil
+	}
+	return appendOperation(operations, OpReplace, path, newValue)
+}
+
+func diffObjects(path string, oldObject, newObject map[string]any, operations *[]Operation) error {
+	keys := make([]string, 0, len(oldObject)+len(newObject))
+	for key := range oldObject {
+		keys = append(keys, key)
+	}
+	for key := range newObject {
+		if _, found := oldObject[key]; !found {
+			keys = append(keys, key)
+		}
+	}
+	slices.Sort(keys)
+
+	for _, key := range keys {
+		oldValue, inOld := oldObject[key]
+		newValue, inNew := newObject[key]
+		var err error
+		switch {
+		case !inNew:
+			err = appendOperation(operations, OpRemove, JoinPath(path, key), nil)
+		case !inOld:
+			err = appendOperation(operations, OpAdd, JoinPath(path, key), newValue)
+		default:
+			err = diff(JoinPath(path, key), oldValue, newValue, operations)
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func appendOperation(operations *[]Operation, op, path string, value any) error {
+	operation := Operation{Op: op, Path: path}
+	if op != OpRemove {
+		rawValue, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("could not encode value at %s: %w", path, err)
+		}
+		operation.Value = rawValue
+	}
+	*operations = append(*operations, operation)
+	return nil
+}
@@ -0,0 +1,88 @@
+package jsondiff_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/jsondiff"
+)
+
+func decode(t *testing.T, document string) any {
+	t.Helper()
+	var value any
+	require.NoError(t, json.Unmarshal([]byte(document), &value))
+	return value
+}
+
+func TestDiff(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name     string
+		old      string
+		new      string
+		expected string
+	}{
+		{
+			name:     "equal documents",
+			old:      `{"channel":"regular","modules":[{"name":"a"}]}`,
+			new:      `{"modules":[{"name":"a"}],"channel":"regular"}`,
+			expected: `[]`,
+		},
+		{
+			name: "added, removed and replaced fields",
+			old:  `{"channel":"regular","sync":{"enabled":true,"strategy":"secret"}}`,
+			new:  `{"channel":"fast","sync":{"enabled":true,"namespace":"kyma-system"}}`,
+			expected: `[
+				{"op":"replace","path":"/spec/channel","value":"fast"},
+				{"op":"add","path":"/spec/sync/namespace","value":"kyma-system"},
+				{"op":"remove","path":"/spec/sync/strategy"}
+			]`,
+		},
+		{
+			name:     "changed array is replaced as a whole",
+			old:      `{"modules":[{"name":"a"},{"name":"b"}]}`,
+			new:      `{"modules":[{"name":"a"}]}`,
+			expected: `[{"op":"replace","path":"/spec/modules","value":[{"name":"a"}]}]`,
+		},
+		{
+			name:     "field replaced with null",
+			old:      `{"channel":"regular"}`,
+			new:      `{"channel":null}`,
+			expected: `[{"op":"replace","path":"/spec/channel","value":null}]`,
+		},
+		{
+			name: "keys are escaped",
+			old:  `{"labels":{"operator.kyma-project.io/managed-by":"a","x~y":"b"}}`,
+			new:  `{"labels":{"operator.kyma-project.io/managed-by":"c","x~y":"d"}}`,
+			expected: `[
+				{"op":"replace","path":"/spec/labels/operator.kyma-project.io~1managed-by","value":"c"},
+				{"op":"replace","path":"/spec/labels/x~0y","value":"d"}
+			]`,
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+
+			operations, err := jsondiff.Diff("/spec", decode(t, testCase.old), decode(t, testCase.new))
+
+			require.NoError(t, err)
+			patch, err := json.Marshal(operations)
+			require.NoError(t, err)
+			assert.JSONEq(t, testCase.expected, string(patch))
+		})
+	}
+}
+
+func TestPaths(t *testing.T) {
+	t.Parallel()
+	operations, err := jsondiff.Diff("/status",
+		decode(t, `{"state":"Processing","conditions":[]}`),
+		decode(t, `{"state":"Ready","conditions":[{"type":"Ready"}]}`))
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"/status/conditions", "/status/state"}, jsondiff.Paths(operations))
+}
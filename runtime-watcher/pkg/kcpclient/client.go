@@ -0,0 +1,128 @@
+package kcpclient
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/cacertificatehandler"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/certwatcher"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var errMissingLeaf = errors.New("tls certificate has no leaf")
+
+type Config struct {
+	// Certificate labels the metrics of the loaded client certificate.
+	Certificate watchermetrics.Certificate
+	CACertPath  string
+	TLSCertPath string
+	TLSKeyPath  string
+	// ReloadInterval is the time between two checks of the certificate files for changes.
+	ReloadInterval time.Duration
+	// Timeout limits the time of a single request including reading the response body.
+	Timeout time.Duration
+}
+
+// Client is a long-lived mTLS client for KCP. Connections are kept alive between requests,
+// and the client certificate and CA pool are reloaded when the mounted files change.
+type Client struct {
+	logger     logr.Logger
+	metrics    *watchermetrics.WatcherMetrics
+	watcher    *certwatcher.Watcher
+	transport  atomic.Pointer[http.Transport]
+	leaf       atomic.Pointer[x509.Certificate]
+	httpClient *http.Client
+}
+
+// New loads the certificates and returns a Client using them.
+func New(logger logr.Logger, config Config, metrics *watchermetrics.WatcherMetrics) (*Client, error) {
+	client := &Client{
+		logger:  logger,
+		metrics: metrics,
+	}
+	client.httpClient = &http.Client{
+		Timeout:   config.Timeout,
+		Transport: client,
+	}
+	client.watcher = certwatcher.New(logger, certwatcher.Config{
+		Certificate: config.Certificate,
+		Paths:       []string{config.TLSCertPath, config.TLSKeyPath, config.CACertPath},
+		Interval:    config.ReloadInterval,
+	}, func() error {
+		return client.load(config)
+	}, metrics)
+
+	err := client.watcher.Load()
+	if err != nil {
+		return nil, err
+	}
+	return client, nil
+}
+
+// HTTPClient returns the http.Client sending requests with the currently loaded certificates.
+func (c *Client) HTTPClient() *http.Client {
+	return c.httpClient
+}
+
+// Check returns an error if the certificate files could not be loaded on the last change
+// or the client certificate is not valid.
+func (c *Client) Check() error {
+	err := c.watcher.Err()
+	if err != nil {
+		return err
+	}
+	return certwatcher.CheckValidity(c.leaf.Load(), time.Now())
+}
+
+// Run reloads the certificates when the files change until ctx is done.
+func (c *Client) Run(ctx context.Context) {
+	c.watcher.Run(ctx)
+}
+
+// RoundTrip sends the request over the transport of the currently loaded certificates.
+func (c *Client) RoundTrip(request *http.Request) (*http.Response, error) {
+	return c.transport.Load().RoundTrip(request)
+}
+
+// load replaces the transport, so new requests use the new certificates while in-flight requests
+// complete on the previous transport. Its idle connections are closed to not reuse the previous certificates.
+func (c *Client) load(config Config) error {
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertPath, config.TLSKeyPath)
+	if err != nil {
+		return fmt.Errorf("could not load tls certificate :%w", err)
+	}
+	if certificate.Leaf == nil {
+		return errMissingLeaf
+	}
+	rootCertPool, err := cacertificatehandler.GetCertificatePool(config.CACertPath)
+	if err != nil {
+		return fmt.Errorf("failed to get certificate pool:%w", err)
+	}
+
+	defaultTransport, _ := http.DefaultTransport.(*http.Transport)
+	transport := defaultTransport.Clone()
+	//nolint:gosec
+	transport.TLSClientConfig = &tls.Config{
+		Certificates: []tls.Certificate{certificate},
+		RootCAs:      rootCertPool,
+	}
+	c.leaf.Store(certificate.Leaf)
+	previous := c.transport.Swap(transport)
+	if previous != nil {
+		previous.CloseIdleConnections()
+	}
+
+	c.metrics.UpdateLoadedCertificate(config.Certificate,
+		certificate.Leaf.SerialNumber.String(), certificate.Leaf.NotAfter)
+	c.logger.Info("loaded KCP client certificate", "serialNumber", certificate.Leaf.SerialNumber.String(),
+		"notAfter", certificate.Leaf.NotAfter)
+	return nil
+}
@@ -0,0 +1,87 @@
+package kcpclient_test
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/kcpclient"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+func newKCPServer(t *testing.T, certProvider *tlstest.CertProvider) *httptest.Server {
+	t.Helper()
+	rootCert, err := x509.ParseCertificate(certProvider.RootCert.Certificate[0])
+	require.NoError(t, err)
+	clientCAs := x509.NewCertPool()
+	clientCAs.AddCert(rootCert)
+
+	server := httptest.NewUnstartedServer(http.HandlerFunc(func(writer http.ResponseWriter, _ *http.Request) {
+		writer.WriteHeader(http.StatusOK)
+	}))
+	server.TLS = &tls.Config{
+		Certificates: []tls.Certificate{*certProvider.ServerCert},
+		ClientCAs:    clientCAs,
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		MinVersion:   tls.VersionTLS13,
+	}
+	server.StartTLS()
+	t.Cleanup(server.Close)
+	return server
+}
+
+func get(client *kcpclient.Client, url string) error {
+	resp, err := client.HTTPClient().Get(url)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+func TestClient_ReloadsCertificatesWhenFilesChange(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	client, err := kcpclient.New(logr.Discard(), kcpclient.Config{
+		Certificate:    watchermetrics.CertificateKCPClient,
+		CACertPath:     certProvider.RootCertFile.Name(),
+		TLSCertPath:    certProvider.ClientCertFile.Name(),
+		TLSKeyPath:     certProvider.ClientKeyFile.Name(),
+		ReloadInterval: 10 * time.Millisecond,
+		Timeout:        time.Second,
+	}, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	go client.Run(t.Context())
+
+	server := newKCPServer(t, certProvider)
+	require.NoError(t, get(client, server.URL))
+
+	// rotate the CA and all certificates signed by it
+	require.NoError(t, certProvider.GenerateCerts())
+	rotatedServer := newKCPServer(t, certProvider)
+
+	require.Eventually(t, func() bool {
+		return get(client, rotatedServer.URL) == nil
+	}, time.Second, 10*time.Millisecond)
+	require.Error(t, get(client, server.URL))
+}
+
+func TestNew_ReturnsErrorForMissingCertificate(t *testing.T) {
+	t.Parallel()
+	_, err := kcpclient.New(logr.Discard(), kcpclient.Config{
+		CACertPath:     "missing-ca.pem",
+		TLSCertPath:    "missing-cert.pem",
+		TLSKeyPath:     "missing-key.pem",
+		ReloadInterval: time.Second,
+	}, watchermetrics.NewMetrics())
+
+	require.Error(t, err)
+}
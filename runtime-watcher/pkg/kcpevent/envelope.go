@@ -0,0 +1,53 @@
+package kcpevent
+
+import (
+	"context"
+	"fmt"
+
+	listenerTypes "github.com/kyma-project/runtime-watcher/listener/pkg/v2/types"
+)
+
+// Envelope is a WatchEvent addressed to the KCP listener of a module.
+type Envelope struct {
+	ModuleName string
+	// ID identifies the admission request the event originates from. It is sent to KCP as header
+	// and reported as audit annotation in SKR, so both sides can be correlated.
+	ID string
+	// ReplacedIDs identifies the admission requests whose events were replaced by this one while they were
+	// pending, so that they are sent to KCP along with ID.
+	ReplacedIDs []string
+	Event       listenerTypes.WatchEvent
+}
+
+// Replacing returns the Envelope replacing the pending one, carrying the IDs of the pending Envelope.
+func (e Envelope) Replacing(pending Envelope) Envelope {
+	e.ReplacedIDs = nil
+	if ids := pending.EventIDs(); len(ids) > 0 {
+		e.ReplacedIDs = ids
+	}
+	return e
+}
+
+// EventIDs returns the IDs of the replaced admission requests followed by ID, skipping empty IDs.
+func (e Envelope) EventIDs() []string {
+	ids := make([]string, 0, len(e.ReplacedIDs)+1)
+	for _, id := range e.ReplacedIDs {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if e.ID != "" {
+		ids = append(ids, e.ID)
+	}
+	return ids
+}
+
+// DeliverFunc delivers Envelopes of the same module to KCP.
+type DeliverFunc func(ctx context.Context, envelopes []Envelope) error
+
+// Key identifies the watched object of the Envelope within its module,
+// e.g. "kyma/operator.kyma-project.io/v1beta2/Kyma/kcp-system/default".
+func (e Envelope) Key() string {
+	gvk := e.Event.WatchedGvk
+	return fmt.Sprintf("%s/%s/%s/%s/%s", e.ModuleName, gvk.Group, gvk.Version, gvk.Kind, e.Event.Watched.String())
+}
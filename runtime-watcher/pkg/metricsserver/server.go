@@ -0,0 +1,167 @@
+package metricsserver
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/admissionreview"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/cacertificatehandler"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/servingcert"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+const (
+	metricsPath  = "/metrics"
+	bearerPrefix = "Bearer "
+)
+
+var errEmptyBearerToken = errors.New("bearer token file is empty")
+
+type Config struct {
+	Port int
+	// LocalhostOnly binds the server to the loopback interface, so the metrics can only be scraped
+	// from within the pod, e.g. by a sidecar.
+	LocalhostOnly bool
+	// TLSCertPath and TLSKeyPath serve the metrics with TLS. Empty serves plain HTTP.
+	TLSCertPath string
+	TLSKeyPath  string
+	// ClientCAPath lets clients with a certificate signed by the CA scrape the metrics. It requires TLS.
+	ClientCAPath string
+	// BearerTokenPath is the file of the bearer token that lets clients scrape the metrics.
+	BearerTokenPath string
+	// ReloadInterval is the time between two checks of the certificate files for changes.
+	ReloadInterval time.Duration
+}
+
+// Server serves the metrics on a mux of its own. If a client CA or a bearer token is configured,
+// only clients presenting a certificate signed by the CA or the bearer token can scrape them.
+type Server struct {
+	logger      logr.Logger
+	server      *http.Server
+	certLoader  *servingcert.Loader
+	bearerToken []byte
+	verifyCerts bool
+}
+
+func New(logger logr.Logger, config Config, metrics *watchermetrics.WatcherMetrics) (*Server, error) {
+	host := ""
+	if config.LocalhostOnly {
+		host = "127.0.0.1"
+	}
+	server := &Server{
+		logger: logger,
+		server: &http.Server{
+			Addr:              net.JoinHostPort(host, strconv.Itoa(config.Port)),
+			ReadHeaderTimeout: admissionreview.HTTPTimeout,
+		},
+	}
+
+	if config.TLSCertPath != "" {
+		err := server.configureTLS(config, metrics)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if config.BearerTokenPath != "" {
+		token, err := os.ReadFile(config.BearerTokenPath)
+		if err != nil {
+			return nil, fmt.Errorf("could not read bearer token: %w", err)
+		}
+		server.bearerToken = bytes.TrimSpace(token)
+		if len(server.bearerToken) == 0 {
+			return nil, errEmptyBearerToken
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, server.authorize(promhttp.Handler()))
+	server.server.Handler = mux
+	return server, nil
+}
+
+func (s *Server) configureTLS(config Config, metrics *watchermetrics.WatcherMetrics) error {
+	certLoader, err := servingcert.New(s.logger.WithName("serving-cert"), servingcert.Config{
+		Certificate:    watchermetrics.CertificateMetricsServing,
+		TLSCertPath:    config.TLSCertPath,
+		TLSKeyPath:     config.TLSKeyPath,
+		ReloadInterval: config.ReloadInterval,
+	}, metrics)
+	if err != nil {
+		return fmt.Errorf("failed to load metrics serving certificate: %w", err)
+	}
+	s.certLoader = certLoader
+	s.server.TLSConfig = &tls.Config{
+		MinVersion:     tls.VersionTLS13,
+		GetCertificate: certLoader.GetCertificate,
+	}
+	if config.ClientCAPath != "" {
+		clientCAs, err := cacertificatehandler.GetCertificatePool(config.ClientCAPath)
+		if err != nil {
+			return fmt.Errorf("failed to load metrics client CA: %w", err)
+		}
+		// clients without certificate may still authenticate with the bearer token
+		s.server.TLSConfig.ClientAuth = tls.VerifyClientCertIfGiven
+		s.server.TLSConfig.ClientCAs = clientCAs
+		s.verifyCerts = true
+	}
+	return nil
+}
+
+// authorize lets a request through if it presents a verified client certificate or the bearer token,
+// or if neither is configured.
+func (s *Server) authorize(next http.Handler) http.Handler {
+	if !s.verifyCerts && s.bearerToken == nil {
+		return next
+	}
+	return http.HandlerFunc(func(writer http.ResponseWriter, request *http.Request) {
+		if s.verifyCerts && request.TLS != nil && len(request.TLS.VerifiedChains) > 0 {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		token, found := strings.CutPrefix(request.Header.Get("Authorization"), bearerPrefix)
+		if found && s.bearerToken != nil && subtle.ConstantTimeCompare([]byte(token), s.bearerToken) == 1 {
+			next.ServeHTTP(writer, request)
+			return
+		}
+		http.Error(writer, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+	})
+}
+
+// ListenAndServe serves the metrics until the server is shut down. The serving certificate is reloaded
+// when its files change until ctx is done.
+func (s *Server) ListenAndServe(ctx context.Context) error {
+	s.logger.Info("Starting metrics server", "Addr", s.server.Addr, "TLS", s.certLoader != nil)
+	var err error
+	if s.certLoader != nil {
+		go s.certLoader.Run(ctx)
+		err = s.server.ListenAndServeTLS("", "")
+	} else {
+		err = s.server.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return fmt.Errorf("failed to serve metrics: %w", err)
+}
+
+// Shutdown stops the server, waiting for in-flight scrapes until ctx is done.
+func (s *Server) Shutdown(ctx context.Context) error {
+	err := s.server.Shutdown(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to shut down metrics server: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,90 @@
+package metricsserver
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+const bearerToken = "scrape-token"
+
+func startServer(t *testing.T, config Config) *httptest.Server {
+	t.Helper()
+	server, err := New(logr.Discard(), config, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	testServer := httptest.NewUnstartedServer(server.server.Handler)
+	if server.server.TLSConfig != nil {
+		testServer.TLS = server.server.TLSConfig
+		testServer.StartTLS()
+	} else {
+		testServer.Start()
+	}
+	t.Cleanup(testServer.Close)
+	return testServer
+}
+
+func scrape(t *testing.T, client *http.Client, url, token string) int {
+	t.Helper()
+	request, err := http.NewRequestWithContext(t.Context(), http.MethodGet, url+metricsPath, nil)
+	require.NoError(t, err)
+	if token != "" {
+		request.Header.Set("Authorization", bearerPrefix+token)
+	}
+	response, err := client.Do(request)
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	return response.StatusCode
+}
+
+func tlsClient(certificates ...tls.Certificate) *http.Client {
+	return &http.Client{Transport: &http.Transport{
+		//nolint:gosec // the test certificates are not issued for the address of the test server
+		TLSClientConfig: &tls.Config{InsecureSkipVerify: true, Certificates: certificates},
+	}}
+}
+
+func TestServer_ServesOnlyMetrics(t *testing.T) {
+	t.Parallel()
+	server := startServer(t, Config{})
+
+	assert.Equal(t, http.StatusOK, scrape(t, server.Client(), server.URL, ""))
+	response, err := server.Client().Get(server.URL + "/validate/kyma")
+	require.NoError(t, err)
+	require.NoError(t, response.Body.Close())
+	assert.Equal(t, http.StatusNotFound, response.StatusCode)
+}
+
+func TestServer_RequiresBearerTokenOrClientCertificate(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	tokenPath := filepath.Join(t.TempDir(), "token")
+	require.NoError(t, os.WriteFile(tokenPath, []byte(bearerToken+"\n"), 0o600))
+	clientCert, err := tls.LoadX509KeyPair(certProvider.ClientCertFile.Name(), certProvider.ClientKeyFile.Name())
+	require.NoError(t, err)
+
+	server := startServer(t, Config{
+		TLSCertPath:     certProvider.ClientCertFile.Name(),
+		TLSKeyPath:      certProvider.ClientKeyFile.Name(),
+		ClientCAPath:    certProvider.RootCertFile.Name(),
+		BearerTokenPath: tokenPath,
+		ReloadInterval:  time.Minute,
+	})
+
+	assert.Equal(t, http.StatusUnauthorized, scrape(t, tlsClient(), server.URL, ""))
+	assert.Equal(t, http.StatusUnauthorized, scrape(t, tlsClient(), server.URL, "wrong-token"))
+	assert.Equal(t, http.StatusOK, scrape(t, tlsClient(), server.URL, bearerToken))
+	assert.Equal(t, http.StatusOK, scrape(t, tlsClient(clientCert), server.URL, ""))
+}
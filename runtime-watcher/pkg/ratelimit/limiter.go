@@ -0,0 +1,119 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// pruneInterval is the time between two removals of the buckets of objects that have been idle
+// long enough to be full again.
+const pruneInterval = time.Minute
+
+// Config of a token bucket. The bucket holds up to Burst tokens and is refilled with EventsPerSecond tokens.
+// Every event takes a token. A zero EventsPerSecond disables the limit.
+type Config struct {
+	EventsPerSecond float64
+	Burst           int
+}
+
+func (c Config) enabled() bool {
+	return c.EventsPerSecond > 0
+}
+
+// Limiter limits the events of a module with a token bucket for the module and one for each object.
+//
+// An event arriving at an empty bucket reserves the next token, so it has to be delayed until the token
+// is added. Events that would have to be delayed longer than the maximum delay take no tokens.
+type Limiter struct {
+	module   Config
+	object   Config
+	maxDelay time.Duration
+
+	mu            sync.Mutex
+	moduleBucket  bucket
+	objectBuckets map[string]*bucket
+	prunedAt      time.Time
+}
+
+func New(module, object Config, maxDelay time.Duration) *Limiter {
+	return &Limiter{
+		module:        module,
+		object:        object,
+		maxDelay:      maxDelay,
+		objectBuckets: map[string]*bucket{},
+	}
+}
+
+// Reserve takes a token from the bucket of the module and of the object with the given key and returns
+// the time until the event may be sent. If it exceeds the maximum delay, no token is taken and false
+// is returned, so the event has to be dropped.
+func (l *Limiter) Reserve(key string, now time.Time) (time.Duration, bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.prune(now)
+
+	var delay time.Duration
+	if l.module.enabled() {
+		l.moduleBucket.refill(l.module, now)
+		delay = l.moduleBucket.delay(l.module)
+	}
+	objectBucket := l.objectBuckets[key]
+	if l.object.enabled() {
+		if objectBucket == nil {
+			objectBucket = &bucket{}
+		}
+		objectBucket.refill(l.object, now)
+		delay = max(delay, objectBucket.delay(l.object))
+	}
+	if delay > l.maxDelay {
+		return 0, false
+	}
+
+	if l.module.enabled() {
+		l.moduleBucket.tokens--
+	}
+	if l.object.enabled() {
+		objectBucket.tokens--
+		l.objectBuckets[key] = objectBucket
+	}
+	return delay, true
+}
+
+// prune removes the buckets of objects that are full, as they do not limit the next event.
+func (l *Limiter) prune(now time.Time) {
+	if now.Sub(l.prunedAt) < pruneInterval {
+		return
+	}
+	l.prunedAt = now
+	for key, objectBucket := range l.objectBuckets {
+		objectBucket.refill(l.object, now)
+		if objectBucket.tokens >= float64(l.object.Burst) {
+			delete(l.objectBuckets, key)
+		}
+	}
+}
+
+// bucket holds the tokens available at the time it was updated. Reserved tokens make it negative.
+type bucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+func (b *bucket) refill(config Config, now time.Time) {
+	if b.updated.IsZero() {
+		b.tokens, b.updated = float64(config.Burst), now
+		return
+	}
+	if elapsed := now.Sub(b.updated); elapsed > 0 {
+		b.tokens = min(float64(config.Burst), b.tokens+elapsed.Seconds()*config.EventsPerSecond)
+		b.updated = now
+	}
+}
+
+// delay returns the time until the bucket holds a token.
+func (b *bucket) delay(config Config) time.Duration {
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.tokens) / config.EventsPerSecond * float64(time.Second))
+}
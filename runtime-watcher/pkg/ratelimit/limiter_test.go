@@ -0,0 +1,67 @@
+package ratelimit_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/ratelimit"
+)
+
+const (
+	kyma  = "kyma/operator.kyma-project.io/v1beta2/Kyma/kcp-system/kyma"
+	other = "kyma/operator.kyma-project.io/v1beta2/Kyma/kcp-system/other"
+)
+
+func reserve(t *testing.T, limiter *ratelimit.Limiter, key string, now time.Time) time.Duration {
+	t.Helper()
+	delay, allowed := limiter.Reserve(key, now)
+	assert.True(t, allowed)
+	return delay
+}
+
+func TestLimiter_DelaysEventsOfObjectExceedingBurst(t *testing.T) {
+	t.Parallel()
+	limiter := ratelimit.New(ratelimit.Config{}, ratelimit.Config{EventsPerSecond: 1, Burst: 2}, time.Minute)
+	now := time.Now()
+
+	assert.Zero(t, reserve(t, limiter, kyma, now))
+	assert.Zero(t, reserve(t, limiter, kyma, now))
+	assert.Equal(t, time.Second, reserve(t, limiter, kyma, now))
+	assert.Equal(t, 2*time.Second, reserve(t, limiter, kyma, now))
+	assert.Zero(t, reserve(t, limiter, other, now))
+}
+
+func TestLimiter_RefillsBucketOverTime(t *testing.T) {
+	t.Parallel()
+	limiter := ratelimit.New(ratelimit.Config{}, ratelimit.Config{EventsPerSecond: 2, Burst: 1}, time.Minute)
+	now := time.Now()
+
+	assert.Zero(t, reserve(t, limiter, kyma, now))
+	assert.Equal(t, 500*time.Millisecond, reserve(t, limiter, kyma, now))
+	assert.Zero(t, reserve(t, limiter, kyma, now.Add(2*time.Second)))
+}
+
+func TestLimiter_DelaysEventsOfModuleExceedingBurst(t *testing.T) {
+	t.Parallel()
+	limiter := ratelimit.New(ratelimit.Config{EventsPerSecond: 10, Burst: 1},
+		ratelimit.Config{EventsPerSecond: 1, Burst: 5}, time.Minute)
+	now := time.Now()
+
+	assert.Zero(t, reserve(t, limiter, kyma, now))
+	assert.Equal(t, 100*time.Millisecond, reserve(t, limiter, other, now))
+}
+
+func TestLimiter_RejectsEventsExceedingMaxDelayWithoutTakingToken(t *testing.T) {
+	t.Parallel()
+	limiter := ratelimit.New(ratelimit.Config{}, ratelimit.Config{EventsPerSecond: 1, Burst: 1}, time.Second)
+	now := time.Now()
+	reserve(t, limiter, kyma, now)
+	reserve(t, limiter, kyma, now)
+
+	_, allowed := limiter.Reserve(kyma, now)
+
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, reserve(t, limiter, kyma, now.Add(time.Second)))
+}
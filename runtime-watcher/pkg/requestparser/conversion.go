@@ -0,0 +1,49 @@
+package requestparser
+
+import (
+	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+)
+
+// convertFromV1beta1 converts a v1beta1 AdmissionReview to v1. Both versions have the same fields,
+// so the conversion is lossless.
+func convertFromV1beta1(review *admissionv1beta1.AdmissionReview) *admissionv1.AdmissionReview {
+	converted := &admissionv1.AdmissionReview{TypeMeta: review.TypeMeta}
+	if request := review.Request; request != nil {
+		converted.Request = &admissionv1.AdmissionRequest{
+			UID:                request.UID,
+			Kind:               request.Kind,
+			Resource:           request.Resource,
+			SubResource:        request.SubResource,
+			RequestKind:        request.RequestKind,
+			RequestResource:    request.RequestResource,
+			RequestSubResource: request.RequestSubResource,
+			Name:               request.Name,
+			Namespace:          request.Namespace,
+			Operation:          admissionv1.Operation(request.Operation),
+			UserInfo:           request.UserInfo,
+			Object:             request.Object,
+			OldObject:          request.OldObject,
+			DryRun:             request.DryRun,
+			Options:            request.Options,
+		}
+	}
+	return converted
+}
+
+// convertToV1beta1 converts a v1 AdmissionReview to v1beta1 to answer a v1beta1 request.
+func convertToV1beta1(review *admissionv1.AdmissionReview) *admissionv1beta1.AdmissionReview {
+	converted := &admissionv1beta1.AdmissionReview{TypeMeta: review.TypeMeta}
+	if response := review.Response; response != nil {
+		converted.Response = &admissionv1beta1.AdmissionResponse{
+			UID:              response.UID,
+			Allowed:          response.Allowed,
+			Result:           response.Result,
+			Patch:            response.Patch,
+			PatchType:        (*admissionv1beta1.PatchType)(response.PatchType),
+			AuditAnnotations: response.AuditAnnotations,
+			Warnings:         response.Warnings,
+		}
+	}
+	return converted
+}
@@ -1,11 +1,15 @@
 package requestparser
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"io"
 	"net/http"
 
 	admissionv1 "k8s.io/api/admission/v1"
+	admissionv1beta1 "k8s.io/api/admission/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -21,8 +25,11 @@ var (
 	errReadRequestBody             = errors.New("io failed to read bytes from request body")
 	errDeserializeRequestBody      = errors.New("serializer failed to decode admission review body")
 	errEmptyAdmissionReviewRequest = errors.New("admission request was empty")
+	errUnsupportedVersion          = errors.New("unsupported admission review version")
 )
 
+// ParseAdmissionReview decodes an admission.k8s.io/v1 or v1beta1 AdmissionReview. A v1beta1 review is
+// converted to v1, which is used internally, while its apiVersion is kept to answer in the same version.
 func (rp *RequestParser) ParseAdmissionReview(request *http.Request) (*admissionv1.AdmissionReview, error) {
 	defer request.Body.Close()
 
@@ -31,13 +38,43 @@ func (rp *RequestParser) ParseAdmissionReview(request *http.Request) (*admission
 		return nil, errors.Join(errReadRequestBody, err)
 	}
 
+	typeMeta := metav1.TypeMeta{}
+	err = json.Unmarshal(bodyBytes, &typeMeta)
+	if err != nil {
+		return nil, errors.Join(errDeserializeRequestBody, err)
+	}
+
 	admissionReview := &admissionv1.AdmissionReview{}
-	_, _, err = rp.deserializer.Decode(bodyBytes, nil, admissionReview)
+	switch typeMeta.APIVersion {
+	// reviews without apiVersion are decoded as v1 as before
+	case "", admissionv1.SchemeGroupVersion.String():
+		_, _, err = rp.deserializer.Decode(bodyBytes, nil, admissionReview)
+	case admissionv1beta1.SchemeGroupVersion.String():
+		v1beta1Review := &admissionv1beta1.AdmissionReview{}
+		_, _, err = rp.deserializer.Decode(bodyBytes, nil, v1beta1Review)
+		admissionReview = convertFromV1beta1(v1beta1Review)
+	default:
+		return nil, fmt.Errorf("%w: %s", errUnsupportedVersion, typeMeta.APIVersion)
+	}
 	if err != nil {
 		return nil, errors.Join(errDeserializeRequestBody, err)
 	}
 	if admissionReview.Request == nil {
-		return nil, errors.Join(errEmptyAdmissionReviewRequest, err)
+		return nil, errEmptyAdmissionReviewRequest
 	}
 	return admissionReview, nil
 }
+
+// MarshalAdmissionReview encodes the AdmissionReview in the version of its apiVersion,
+// converting it to v1beta1 if the request was received in that version.
+func MarshalAdmissionReview(admissionReview *admissionv1.AdmissionReview) ([]byte, error) {
+	var review any = admissionReview
+	if admissionReview.APIVersion == admissionv1beta1.SchemeGroupVersion.String() {
+		review = convertToV1beta1(admissionReview)
+	}
+	reviewBytes, err := json.Marshal(review)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode admission review: %w", err)
+	}
+	return reviewBytes, nil
+}
@@ -0,0 +1,130 @@
+package requestparser_test
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	admissionv1 "k8s.io/api/admission/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/requestparser"
+)
+
+const admissionRequest = `"request":{
+	"uid":"705ab4f5-6393-11e8-b7cc-42010a800002",
+	"kind":{"group":"operator.kyma-project.io","version":"v1beta2","kind":"Kyma"},
+	"resource":{"group":"operator.kyma-project.io","version":"v1beta2","resource":"kymas"},
+	"name":"default","namespace":"kyma-system","operation":"UPDATE","dryRun":true,
+	"userInfo":{"username":"kubernetes-admin","groups":["system:masters"]},
+	"object":{"metadata":{"name":"default"}},"oldObject":{"metadata":{"name":"default"}}
+}`
+
+func newRequest(t *testing.T, body string) *http.Request {
+	t.Helper()
+	return httptest.NewRequestWithContext(t.Context(), http.MethodPost, "/validate/kyma", bytes.NewBufferString(body))
+}
+
+func TestParseAdmissionReview(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name       string
+		body       string
+		apiVersion string
+	}{
+		{
+			name:       "v1",
+			body:       `{"apiVersion":"admission.k8s.io/v1","kind":"AdmissionReview",` + admissionRequest + `}`,
+			apiVersion: "admission.k8s.io/v1",
+		},
+		{
+			name:       "v1beta1",
+			body:       `{"apiVersion":"admission.k8s.io/v1beta1","kind":"AdmissionReview",` + admissionRequest + `}`,
+			apiVersion: "admission.k8s.io/v1beta1",
+		},
+		{
+			name:       "without apiVersion",
+			body:       `{` + admissionRequest + `}`,
+			apiVersion: "",
+		},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			parser := requestparser.NewRequestParser(
+				serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer())
+
+			review, err := parser.ParseAdmissionReview(newRequest(t, testCase.body))
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.apiVersion, review.APIVersion)
+			request := review.Request
+			require.NotNil(t, request)
+			assert.Equal(t, admissionv1.Update, request.Operation)
+			assert.Equal(t, "kymas", request.Resource.Resource)
+			assert.Equal(t, "kubernetes-admin", request.UserInfo.Username)
+			assert.JSONEq(t, `{"metadata":{"name":"default"}}`, string(request.Object.Raw))
+			require.NotNil(t, request.DryRun)
+			assert.True(t, *request.DryRun)
+		})
+	}
+}
+
+func TestParseAdmissionReview_ReturnsError(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "unsupported version", body: `{"apiVersion":"admission.k8s.io/v2",` + admissionRequest + `}`},
+		{name: "missing request", body: `{"apiVersion":"admission.k8s.io/v1beta1","kind":"AdmissionReview"}`},
+		{name: "invalid json", body: `{"apiVersion":`},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.name, func(t *testing.T) {
+			t.Parallel()
+			parser := requestparser.NewRequestParser(
+				serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer())
+
+			_, err := parser.ParseAdmissionReview(newRequest(t, testCase.body))
+
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestMarshalAdmissionReview_AnswersInRequestVersion(t *testing.T) {
+	t.Parallel()
+	for _, apiVersion := range []string{"admission.k8s.io/v1", "admission.k8s.io/v1beta1"} {
+		t.Run(apiVersion, func(t *testing.T) {
+			t.Parallel()
+			review := &admissionv1.AdmissionReview{
+				TypeMeta: metav1.TypeMeta{Kind: "AdmissionReview", APIVersion: apiVersion},
+				Response: &admissionv1.AdmissionResponse{
+					UID:              "705ab4f5-6393-11e8-b7cc-42010a800002",
+					Allowed:          true,
+					Result:           &metav1.Status{Status: metav1.StatusSuccess, Message: "kcp request succeeded"},
+					AuditAnnotations: map[string]string{"event-id": "1"},
+					Warnings:         []string{"warning"},
+				},
+			}
+
+			reviewBytes, err := requestparser.MarshalAdmissionReview(review)
+
+			require.NoError(t, err)
+			assert.JSONEq(t, `{
+				"apiVersion":"`+apiVersion+`","kind":"AdmissionReview",
+				"response":{
+					"uid":"705ab4f5-6393-11e8-b7cc-42010a800002","allowed":true,
+					"status":{"metadata":{},"status":"Success","message":"kcp request succeeded"},
+					"auditAnnotations":{"event-id":"1"},"warnings":["warning"]
+				}
+			}`, string(reviewBytes))
+		})
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-logr/logr"
 )
@@ -21,11 +22,54 @@ const (
 	envTLSKey          = "TLS_KEY"
 	envKCPAddress      = "KCP_ADDR"
 	envKCPContract     = "KCP_CONTRACT" // 31536000 seconds = 1 year
+
+	envCertReloadInterval     = "CERT_RELOAD_INTERVAL"
+	defaultCertReloadInterval = 30 * time.Second
+
+	envForwardingWorkers      = "FORWARDING_WORKERS"
+	envForwardingQueueSize    = "FORWARDING_QUEUE_SIZE"
+	envForwardingMaxRetries   = "FORWARDING_MAX_RETRIES"
+	envForwardingRetryBackoff = "FORWARDING_RETRY_BACKOFF"
+	envForwardingDrainTimeout = "FORWARDING_DRAIN_TIMEOUT"
+	envForwardingBatchSize    = "FORWARDING_BATCH_SIZE"
+
+	envSpoolDir            = "SPOOL_DIR"
+	envSpoolMaxBytes       = "SPOOL_MAX_BYTES"
+	envSpoolMaxAge         = "SPOOL_MAX_AGE"
+	envSpoolReplayInterval = "SPOOL_REPLAY_INTERVAL"
+
+	envCircuitBreakerFailureThreshold = "CIRCUIT_BREAKER_FAILURE_THRESHOLD"
+	envCircuitBreakerProbeInterval    = "CIRCUIT_BREAKER_PROBE_INTERVAL"
+
+	envReadinessKCPWindow = "READINESS_KCP_WINDOW"
+
+	envMetricsLocalhostOnly   = "METRICS_LOCALHOST_ONLY"
+	envMetricsTLSCert         = "METRICS_TLS_CERT"
+	envMetricsTLSKey          = "METRICS_TLS_KEY"
+	envMetricsClientCA        = "METRICS_CLIENT_CA"
+	envMetricsBearerTokenFile = "METRICS_BEARER_TOKEN_FILE"
+
+	defaultForwardingWorkers      = 4
+	defaultForwardingQueueSize    = 1000
+	defaultForwardingMaxRetries   = 3
+	defaultForwardingRetryBackoff = time.Second
+	defaultForwardingDrainTimeout = 30 * time.Second
+	defaultForwardingBatchSize    = 20
+
+	defaultSpoolMaxBytes       = 10 * 1024 * 1024 // 10 MiB
+	defaultSpoolMaxAge         = time.Hour
+	defaultSpoolReplayInterval = 30 * time.Second
+
+	defaultCircuitBreakerFailureThreshold = 5
+	defaultCircuitBreakerProbeInterval    = 30 * time.Second
 )
 
 var (
 	errInvalidPortRange   = errors.New("invalid port range")
 	errParsingEnvVariable = errors.New("error parsing env variable")
+	errValueOutOfRange    = errors.New("value out of range")
+	errIncompleteTLS      = errors.New("certificate and key must be set together")
+	errClientCAWithoutTLS = errors.New("client CA requires TLS")
 )
 
 type ServerConfig struct {
@@ -36,6 +80,49 @@ type ServerConfig struct {
 	TLSKeyPath  string
 	KCPAddress  string
 	KCPContract string
+
+	// CertReloadInterval is the time between two checks of the mounted certificate files for changes.
+	CertReloadInterval time.Duration
+
+	// ForwardingWorkers is the number of workers delivering events to KCP asynchronously.
+	// 0 disables the forwarding queue and events are delivered within the admission request.
+	ForwardingWorkers      int
+	ForwardingQueueSize    int
+	ForwardingMaxRetries   int
+	ForwardingRetryBackoff time.Duration
+	// ForwardingDrainTimeout is the grace period on shutdown for in-flight admission requests
+	// and pending deliveries to KCP.
+	ForwardingDrainTimeout time.Duration
+	// ForwardingBatchSize is the maximum number of events sent in one request under the batch contract.
+	ForwardingBatchSize int
+
+	// SpoolDir is the directory of the spool for undeliverable events. Empty disables the spool.
+	SpoolDir            string
+	SpoolMaxBytes       int
+	SpoolMaxAge         time.Duration
+	SpoolReplayInterval time.Duration
+
+	// CircuitBreakerFailureThreshold is the number of consecutive failed KCP requests after which
+	// requests fail fast until a probe succeeds. 0 disables the circuit breaker.
+	CircuitBreakerFailureThreshold int
+	CircuitBreakerProbeInterval    time.Duration
+
+	// ReadinessKCPWindow marks the watcher unready if requests to KCP fail and KCP did not respond
+	// within the window. Zero disables the check.
+	ReadinessKCPWindow time.Duration
+
+	// MetricsLocalhostOnly binds the metrics server to the loopback interface.
+	MetricsLocalhostOnly bool
+	// MetricsTLSCertPath and MetricsTLSKeyPath serve the metrics with TLS. Empty serves plain HTTP.
+	MetricsTLSCertPath string
+	MetricsTLSKeyPath  string
+	// MetricsClientCAPath lets clients with a certificate signed by the CA scrape the metrics.
+	MetricsClientCAPath string
+	// MetricsBearerTokenPath is the file of the bearer token that lets clients scrape the metrics.
+	MetricsBearerTokenPath string
+
+	ModuleConfigPath string
+	Modules          map[string]ModuleConfig
 }
 
 func ParseFromEnv(logger logr.Logger) (ServerConfig, error) {
@@ -89,9 +176,117 @@ func ParseFromEnv(logger logr.Logger) (ServerConfig, error) {
 		return config, flagError(envKCPContract)
 	}
 
+	config.CertReloadInterval = durationFromEnv(logger, envCertReloadInterval, defaultCertReloadInterval)
+	parseForwardingConfig(logger, &config)
+	parseSpoolConfig(logger, &config)
+	config.CircuitBreakerFailureThreshold = intFromEnv(logger, envCircuitBreakerFailureThreshold,
+		defaultCircuitBreakerFailureThreshold, 0)
+	config.CircuitBreakerProbeInterval = durationFromEnv(logger, envCircuitBreakerProbeInterval,
+		defaultCircuitBreakerProbeInterval)
+	config.ReadinessKCPWindow = durationFromEnv(logger, envReadinessKCPWindow, 0)
+	err := parseMetricsServerConfig(logger, &config)
+	if err != nil {
+		return config, err
+	}
+
+	config.ModuleConfigPath = os.Getenv(envModuleConfig)
+	if config.ModuleConfigPath != "" {
+		modules, err := parseModuleConfigFile(config.ModuleConfigPath)
+		if err != nil {
+			return config, fmt.Errorf("%w: %w", flagError(envModuleConfig), err)
+		}
+		config.Modules = modules
+	}
+
 	return config, nil
 }
 
+func parseForwardingConfig(logger logr.Logger, config *ServerConfig) {
+	config.ForwardingWorkers = intFromEnv(logger, envForwardingWorkers, defaultForwardingWorkers, 0)
+	config.ForwardingQueueSize = intFromEnv(logger, envForwardingQueueSize, defaultForwardingQueueSize, 1)
+	config.ForwardingMaxRetries = intFromEnv(logger, envForwardingMaxRetries, defaultForwardingMaxRetries, 0)
+	config.ForwardingRetryBackoff = durationFromEnv(logger, envForwardingRetryBackoff,
+		defaultForwardingRetryBackoff)
+	config.ForwardingDrainTimeout = durationFromEnv(logger, envForwardingDrainTimeout,
+		defaultForwardingDrainTimeout)
+	config.ForwardingBatchSize = intFromEnv(logger, envForwardingBatchSize, defaultForwardingBatchSize, 1)
+}
+
+func parseMetricsServerConfig(logger logr.Logger, config *ServerConfig) error {
+	config.MetricsLocalhostOnly = boolFromEnv(logger, envMetricsLocalhostOnly, false)
+	config.MetricsTLSCertPath = os.Getenv(envMetricsTLSCert)
+	config.MetricsTLSKeyPath = os.Getenv(envMetricsTLSKey)
+	config.MetricsClientCAPath = os.Getenv(envMetricsClientCA)
+	config.MetricsBearerTokenPath = os.Getenv(envMetricsBearerTokenFile)
+	if (config.MetricsTLSCertPath == "") != (config.MetricsTLSKeyPath == "") {
+		return fmt.Errorf("%w: %w", flagError(envMetricsTLSCert), errIncompleteTLS)
+	}
+	if config.MetricsClientCAPath != "" && config.MetricsTLSCertPath == "" {
+		return fmt.Errorf("%w: %w", flagError(envMetricsClientCA), errClientCAWithoutTLS)
+	}
+	return nil
+}
+
+func parseSpoolConfig(logger logr.Logger, config *ServerConfig) {
+	config.SpoolDir = os.Getenv(envSpoolDir)
+	config.SpoolMaxBytes = intFromEnv(logger, envSpoolMaxBytes, defaultSpoolMaxBytes, 1)
+	config.SpoolMaxAge = durationFromEnv(logger, envSpoolMaxAge, defaultSpoolMaxAge)
+	config.SpoolReplayInterval = durationFromEnv(logger, envSpoolReplayInterval, defaultSpoolReplayInterval)
+}
+
+// intFromEnv returns the value of the env variable if it is an integer not lower than minValue,
+// otherwise the error is logged and defaultValue is returned.
+func intFromEnv(logger logr.Logger, envName string, defaultValue, minValue int) int {
+	rawValue, found := os.LookupEnv(envName)
+	if !found {
+		return defaultValue
+	}
+	value, err := strconv.Atoi(rawValue)
+	if err != nil {
+		logger.Error(err, flagError(envName).Error())
+		return defaultValue
+	}
+	if value < minValue {
+		logger.Error(errValueOutOfRange, flagError(envName).Error())
+		return defaultValue
+	}
+	return value
+}
+
+// boolFromEnv returns the value of the env variable if it is a boolean,
+// otherwise the error is logged and defaultValue is returned.
+func boolFromEnv(logger logr.Logger, envName string, defaultValue bool) bool {
+	rawValue, found := os.LookupEnv(envName)
+	if !found {
+		return defaultValue
+	}
+	value, err := strconv.ParseBool(rawValue)
+	if err != nil {
+		logger.Error(err, flagError(envName).Error())
+		return defaultValue
+	}
+	return value
+}
+
+// durationFromEnv returns the value of the env variable if it is a positive duration,
+// otherwise the error is logged and defaultValue is returned.
+func durationFromEnv(logger logr.Logger, envName string, defaultValue time.Duration) time.Duration {
+	rawValue, found := os.LookupEnv(envName)
+	if !found {
+		return defaultValue
+	}
+	value, err := time.ParseDuration(rawValue)
+	if err != nil {
+		logger.Error(err, flagError(envName).Error())
+		return defaultValue
+	}
+	if value <= 0 {
+		logger.Error(errValueOutOfRange, flagError(envName).Error())
+		return defaultValue
+	}
+	return value
+}
+
 func validatePortRange(port int) error {
 	if port <= minPort || port >= maxPort {
 		return errInvalidPortRange
@@ -113,6 +308,26 @@ func (s *ServerConfig) PrettyPrint() string {
 		fmt.Sprintf("%s: %s", envTLSKey, s.TLSKeyPath),
 		fmt.Sprintf("%s: %s", envKCPAddress, s.KCPAddress),
 		fmt.Sprintf("%s: %s", envKCPContract, s.KCPContract),
+		fmt.Sprintf("%s: %s", envCertReloadInterval, s.CertReloadInterval),
+		fmt.Sprintf("%s: %d", envForwardingWorkers, s.ForwardingWorkers),
+		fmt.Sprintf("%s: %d", envForwardingQueueSize, s.ForwardingQueueSize),
+		fmt.Sprintf("%s: %d", envForwardingMaxRetries, s.ForwardingMaxRetries),
+		fmt.Sprintf("%s: %s", envForwardingRetryBackoff, s.ForwardingRetryBackoff),
+		fmt.Sprintf("%s: %s", envForwardingDrainTimeout, s.ForwardingDrainTimeout),
+		fmt.Sprintf("%s: %d", envForwardingBatchSize, s.ForwardingBatchSize),
+		fmt.Sprintf("%s: %s", envSpoolDir, s.SpoolDir),
+		fmt.Sprintf("%s: %d", envSpoolMaxBytes, s.SpoolMaxBytes),
+		fmt.Sprintf("%s: %s", envSpoolMaxAge, s.SpoolMaxAge),
+		fmt.Sprintf("%s: %s", envSpoolReplayInterval, s.SpoolReplayInterval),
+		fmt.Sprintf("%s: %d", envCircuitBreakerFailureThreshold, s.CircuitBreakerFailureThreshold),
+		fmt.Sprintf("%s: %s", envCircuitBreakerProbeInterval, s.CircuitBreakerProbeInterval),
+		fmt.Sprintf("%s: %s", envReadinessKCPWindow, s.ReadinessKCPWindow),
+		fmt.Sprintf("%s: %t", envMetricsLocalhostOnly, s.MetricsLocalhostOnly),
+		fmt.Sprintf("%s: %s", envMetricsTLSCert, s.MetricsTLSCertPath),
+		fmt.Sprintf("%s: %s", envMetricsTLSKey, s.MetricsTLSKeyPath),
+		fmt.Sprintf("%s: %s", envMetricsClientCA, s.MetricsClientCAPath),
+		fmt.Sprintf("%s: %s", envMetricsBearerTokenFile, s.MetricsBearerTokenPath),
+		fmt.Sprintf("%s: %s", envModuleConfig, s.ModuleConfigPath),
 	}
 	return strings.Join(configValues, "\n")
 }
@@ -2,12 +2,17 @@
 package serverconfig_test
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/go-logr/logr"
 	"github.com/kyma-project/runtime-watcher/skr/pkg/serverconfig"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"go.uber.org/zap/zapcore"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 func Test_ParseFromEnv_PortUnsetShouldUseDefaultValue(t *testing.T) {
@@ -141,3 +146,185 @@ func setTestDefaults(t *testing.T) {
 	t.Setenv("KCP_ADDR", "address")
 	t.Setenv("KCP_CONTRACT", "contract")
 }
+
+func Test_ParseFromEnv_ModuleConfig(t *testing.T) {
+	setTestDefaults(t)
+	path := filepath.Join(t.TempDir(), "modules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("kyma:\n  debounceWindow: 5s\n  changeSummary: patch\n"+
+		"  forwardDryRun: true\n"+
+		"  watchedFields:\n  - .metadata.labels\n  - .status.conditions\n"+
+		"  ignoredFields:\n  - .status.conditions[*].lastTransitionTime\n"+
+		"  userFilter:\n    exclude:\n    - serviceAccount: kcp-system/lifecycle-manager\n"+
+		"  rateLimit:\n    module:\n      eventsPerSecond: 2.5\n    object:\n      eventsPerSecond: 0.2\n      burst: 3\n"),
+		0o600))
+	t.Setenv("MODULE_CONFIG", path)
+	logger := logr.FromContextOrDiscard(t.Context())
+
+	result, err := serverconfig.ParseFromEnv(logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, 5*time.Second, result.ModuleConfig("kyma").DebounceWindow.Duration)
+	assert.Equal(t, serverconfig.ChangeSummaryPatch, result.ModuleConfig("kyma").ChangeSummary)
+	assert.Equal(t, 4096, result.ModuleConfig("kyma").MaxPatchSize)
+	require.Len(t, result.ModuleConfig("kyma").WatchedFieldPaths(), 2)
+	assert.Equal(t, "/status/conditions", result.ModuleConfig("kyma").WatchedFieldPaths()[1].Pointer())
+	require.Len(t, result.ModuleConfig("kyma").IgnoredFieldPaths(), 1)
+	assert.True(t, result.ModuleConfig("kyma").ForwardDryRun)
+	assert.False(t, result.ModuleConfig("other").ForwardDryRun)
+	require.Len(t, result.ModuleConfig("kyma").UserFilter.Exclude, 1)
+	assert.Equal(t, "system:serviceaccount:kcp-system:lifecycle-manager",
+		result.ModuleConfig("kyma").UserFilter.Exclude[0].ServiceAccountUsername())
+	assert.True(t, result.ModuleConfig("other").UserFilter.IsEmpty())
+	assert.Equal(t, serverconfig.RateLimit{
+		Module:   serverconfig.TokenBucket{EventsPerSecond: 2.5, Burst: 3},
+		Object:   serverconfig.TokenBucket{EventsPerSecond: 0.2, Burst: 3},
+		MaxDelay: metav1.Duration{Duration: time.Minute},
+	}, result.ModuleConfig("kyma").RateLimit)
+	assert.True(t, result.ModuleConfig("other").RateLimit.IsEmpty())
+	assert.Zero(t, result.ModuleConfig("other").DebounceWindow.Duration)
+	assert.Equal(t, serverconfig.ChangeSummaryNone, result.ModuleConfig("other").ChangeSummary)
+}
+
+func Test_ParseFromEnv_ModuleDestinationsDefaultToGlobalValues(t *testing.T) {
+	setTestDefaults(t)
+	path := filepath.Join(t.TempDir(), "modules.yaml")
+	require.NoError(t, os.WriteFile(path, []byte("kyma:\n  destination:\n    address: gateway\n    contract: v3\n"+
+		"other:\n  destination:\n    tlsCertPath: other.crt\n    tlsKeyPath: other.key\n"+
+		"  shadowDestinations:\n  - address: shadow\n  - address: gateway\n    mode: required\n"), 0o600))
+	t.Setenv("MODULE_CONFIG", path)
+	logger := logr.FromContextOrDiscard(t.Context())
+
+	result, err := serverconfig.ParseFromEnv(logger)
+
+	require.NoError(t, err)
+	global := serverconfig.Destination{
+		Address: "address", Contract: "contract", CACertPath: "tmp", TLSCertPath: "tmp", TLSKeyPath: "tmp",
+		Mode: serverconfig.DeliveryRequired,
+	}
+	kyma := global
+	kyma.Address, kyma.Contract = "gateway", "v3"
+	other := global
+	other.TLSCertPath, other.TLSKeyPath = "other.crt", "other.key"
+	shadow := global
+	shadow.Address, shadow.Mode = "shadow", serverconfig.DeliveryBestEffort
+	requiredShadow := global
+	requiredShadow.Address = "gateway"
+	assert.Equal(t, []serverconfig.Destination{kyma}, result.Destinations("kyma"))
+	assert.Equal(t, []serverconfig.Destination{other, shadow, requiredShadow}, result.Destinations("other"))
+	assert.Equal(t, []serverconfig.Destination{global}, result.Destinations("unconfigured"))
+	assert.Equal(t, []serverconfig.Destination{global, kyma, other, shadow, requiredShadow},
+		result.AllDestinations())
+}
+
+func Test_ParseFromEnv_InvalidModuleConfigShouldReturnError(t *testing.T) {
+	for _, content := range []string{
+		"kyma:\n  unknownField: true\n",
+		"kyma:\n  changeSummary: everything\n",
+		"kyma:\n  watchedFields:\n  - metadata\n",
+		"kyma:\n  watchedFields:\n  - .status.conditions[*].type\n",
+		"kyma:\n  ignoredFields:\n  - .status.conditions[*]\n",
+		"kyma:\n  destination:\n    tlsCertPath: tls.crt\n",
+		"kyma:\n  destination:\n    mode: best-effort\n",
+		"kyma:\n  shadowDestinations:\n  - contract: v3\n",
+		"kyma:\n  shadowDestinations:\n  - address: shadow\n    mode: sometimes\n",
+		"kyma:\n  userFilter:\n    exclude:\n    - {}\n",
+		"kyma:\n  userFilter:\n    include:\n    - serviceAccount: lifecycle-manager\n",
+		"kyma:\n  rateLimit:\n    object:\n      eventsPerSecond: -1\n",
+	} {
+		setTestDefaults(t)
+		path := filepath.Join(t.TempDir(), "modules.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		t.Setenv("MODULE_CONFIG", path)
+		logger := logr.FromContextOrDiscard(t.Context())
+
+		_, err := serverconfig.ParseFromEnv(logger)
+
+		assert.Error(t, err, content)
+	}
+}
+
+func Test_ParseFromEnv_MetricsServerConfig(t *testing.T) {
+	setTestDefaults(t)
+	t.Setenv("METRICS_LOCALHOST_ONLY", "true")
+	t.Setenv("METRICS_TLS_CERT", "metrics.crt")
+	t.Setenv("METRICS_TLS_KEY", "metrics.key")
+	t.Setenv("METRICS_CLIENT_CA", "prometheus-ca.crt")
+	t.Setenv("METRICS_BEARER_TOKEN_FILE", "token")
+	logger := logr.FromContextOrDiscard(t.Context())
+
+	result, err := serverconfig.ParseFromEnv(logger)
+
+	require.NoError(t, err)
+	assert.True(t, result.MetricsLocalhostOnly)
+	assert.Equal(t, "metrics.crt", result.MetricsTLSCertPath)
+	assert.Equal(t, "metrics.key", result.MetricsTLSKeyPath)
+	assert.Equal(t, "prometheus-ca.crt", result.MetricsClientCAPath)
+	assert.Equal(t, "token", result.MetricsBearerTokenPath)
+}
+
+func Test_ParseFromEnv_MetricsClientCAWithoutTLSShouldReturnError(t *testing.T) {
+	setTestDefaults(t)
+	t.Setenv("METRICS_CLIENT_CA", "prometheus-ca.crt")
+	logger := logr.FromContextOrDiscard(t.Context())
+
+	_, err := serverconfig.ParseFromEnv(logger)
+
+	assert.Error(t, err)
+}
+
+func Test_ParseLogConfigFromEnv_Defaults(t *testing.T) {
+	production, err := serverconfig.ParseLogConfigFromEnv(false)
+	require.NoError(t, err)
+	assert.Equal(t, serverconfig.LogConfig{Level: zapcore.InfoLevel, Format: serverconfig.LogFormatJSON,
+		Sampling: true}, production)
+
+	development, err := serverconfig.ParseLogConfigFromEnv(true)
+	require.NoError(t, err)
+	assert.Equal(t, serverconfig.LogConfig{Level: zapcore.DebugLevel, Format: serverconfig.LogFormatConsole,
+		Sampling: false}, development)
+}
+
+func Test_ParseLogConfigFromEnv(t *testing.T) {
+	t.Setenv("LOG_LEVEL", "2")
+	t.Setenv("LOG_FORMAT", "console")
+	t.Setenv("LOG_SAMPLING", "false")
+
+	result, err := serverconfig.ParseLogConfigFromEnv(false)
+
+	require.NoError(t, err)
+	assert.Equal(t, zapcore.Level(-2), result.Level)
+	assert.Equal(t, serverconfig.LogFormatConsole, result.Format)
+	assert.False(t, result.Sampling)
+}
+
+func Test_ParseLogConfigFromEnv_InvalidValuesShouldReturnError(t *testing.T) {
+	tests := []struct {
+		env   string
+		value string
+	}{
+		{"LOG_LEVEL", "verbose"},
+		{"LOG_LEVEL", "-1"},
+		{"LOG_LEVEL", "fatal"},
+		{"LOG_FORMAT", "text"},
+		{"LOG_SAMPLING", "sometimes"},
+	}
+	for _, testCase := range tests {
+		t.Run(testCase.env+"="+testCase.value, func(t *testing.T) {
+			t.Setenv(testCase.env, testCase.value)
+
+			_, err := serverconfig.ParseLogConfigFromEnv(false)
+
+			require.ErrorContains(t, err, testCase.env)
+		})
+	}
+}
+
+func Test_ParseFromEnv_ForwardsAsynchronouslyByDefault(t *testing.T) {
+	setTestDefaults(t)
+	logger := logr.FromContextOrDiscard(t.Context())
+
+	result, err := serverconfig.ParseFromEnv(logger)
+
+	require.NoError(t, err)
+	assert.Equal(t, 4, result.ForwardingWorkers)
+}
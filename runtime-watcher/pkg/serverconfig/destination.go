@@ -0,0 +1,110 @@
+package serverconfig
+
+import (
+	"cmp"
+	"errors"
+	"maps"
+	"slices"
+)
+
+// DeliveryMode selects whether a failed delivery to a destination fails the delivery of the event.
+type DeliveryMode string
+
+const (
+	// DeliveryRequired destinations must accept the event, otherwise it is retried or spooled.
+	DeliveryRequired DeliveryMode = "required"
+	// DeliveryBestEffort destinations receive the event in the background alongside the required destinations.
+	// Their failures are only logged and counted.
+	DeliveryBestEffort DeliveryMode = "best-effort"
+)
+
+var (
+	errIncompleteClientCert = errors.New("tlsCertPath and tlsKeyPath must be set together")
+	errInvalidDeliveryMode  = errors.New("mode must be required or best-effort")
+	errBestEffortPrimary    = errors.New("mode of the destination must be required")
+	errMissingShadowAddress = errors.New("address of a shadow destination must be set")
+)
+
+// Destination is the KCP listener receiving the events of a module. Unset fields default to
+// the global KCP_ADDR, KCP_CONTRACT, CA_CERT, TLS_CERT and TLS_KEY.
+type Destination struct {
+	// Address is the host and optional port of the KCP gateway.
+	Address string `json:"address,omitempty"`
+	// Contract is the contract version of the KCP listener, e.g. v2.
+	Contract string `json:"contract,omitempty"`
+	// CACertPath is the CA bundle verifying the KCP gateway.
+	CACertPath string `json:"caCertPath,omitempty"`
+	// TLSCertPath and TLSKeyPath are the client certificate presented to the KCP gateway.
+	TLSCertPath string `json:"tlsCertPath,omitempty"`
+	TLSKeyPath  string `json:"tlsKeyPath,omitempty"`
+	// Mode defaults to required for the destination and to best-effort for shadow destinations.
+	Mode DeliveryMode `json:"mode,omitempty"`
+}
+
+func (d Destination) validate() error {
+	if (d.TLSCertPath == "") != (d.TLSKeyPath == "") {
+		return errIncompleteClientCert
+	}
+	switch d.Mode {
+	case "", DeliveryRequired, DeliveryBestEffort:
+		return nil
+	default:
+		return errInvalidDeliveryMode
+	}
+}
+
+func validateDestinations(module ModuleConfig) error {
+	err := module.Destination.validate()
+	if err != nil {
+		return err
+	}
+	if module.Destination.Mode == DeliveryBestEffort {
+		return errBestEffortPrimary
+	}
+	for _, shadow := range module.ShadowDestinations {
+		err = shadow.validate()
+		if err != nil {
+			return err
+		}
+		if shadow.Address == "" {
+			return errMissingShadowAddress
+		}
+	}
+	return nil
+}
+
+// Destinations returns where the events of the given module are sent to,
+// starting with its destination followed by its shadow destinations.
+func (s *ServerConfig) Destinations(moduleName string) []Destination {
+	module := s.ModuleConfig(moduleName)
+	destinations := make([]Destination, 0, 1+len(module.ShadowDestinations))
+	destinations = append(destinations, s.withDefaults(module.Destination, DeliveryRequired))
+	for _, shadow := range module.ShadowDestinations {
+		destinations = append(destinations, s.withDefaults(shadow, DeliveryBestEffort))
+	}
+	return destinations
+}
+
+// AllDestinations returns the distinct destinations of all modules, starting with the global one.
+func (s *ServerConfig) AllDestinations() []Destination {
+	destinations := []Destination{s.withDefaults(Destination{}, DeliveryRequired)}
+	for _, moduleName := range slices.Sorted(maps.Keys(s.Modules)) {
+		for _, destination := range s.Destinations(moduleName) {
+			if !slices.Contains(destinations, destination) {
+				destinations = append(destinations, destination)
+			}
+		}
+	}
+	return destinations
+}
+
+func (s *ServerConfig) withDefaults(destination Destination, mode DeliveryMode) Destination {
+	destination.Address = cmp.Or(destination.Address, s.KCPAddress)
+	destination.Contract = cmp.Or(destination.Contract, s.KCPContract)
+	destination.CACertPath = cmp.Or(destination.CACertPath, s.CACertPath)
+	if destination.TLSCertPath == "" {
+		destination.TLSCertPath, destination.TLSKeyPath = s.TLSCertPath, s.TLSKeyPath
+	}
+	destination.Mode = cmp.Or(destination.Mode, mode)
+	return destination
+}
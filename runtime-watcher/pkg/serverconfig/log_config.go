@@ -0,0 +1,89 @@
+package serverconfig
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strconv"
+
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	envLogLevel    = "LOG_LEVEL"
+	envLogFormat   = "LOG_FORMAT"
+	envLogSampling = "LOG_SAMPLING"
+)
+
+type LogFormat string
+
+const (
+	LogFormatJSON    LogFormat = "json"
+	LogFormatConsole LogFormat = "console"
+)
+
+var (
+	errInvalidLogLevel  = errors.New("log level must be debug, info, warn, error or a verbosity of at least 0")
+	errInvalidLogFormat = errors.New("log format must be json or console")
+)
+
+// LogConfig configures the logger of the watcher. It is parsed before the logger is created,
+// so invalid values are returned as error instead of being logged.
+type LogConfig struct {
+	// Level is the lowest level of logged entries. The verbosity n of a logr logger, e.g. V(2),
+	// is logged at the zap level -n, so debug logs V(1) and -2 logs V(2).
+	Level zapcore.Level
+	// Format is the encoding of the log entries.
+	Format LogFormat
+	// Sampling limits the entries with the same level and message logged per second,
+	// so that a burst of admission requests does not flood the logs.
+	Sampling bool
+}
+
+// ParseLogConfigFromEnv reads the log config from the environment. Development selects
+// the defaults for running the watcher locally: debug level, console format and no sampling.
+func ParseLogConfigFromEnv(development bool) (LogConfig, error) {
+	config := LogConfig{Level: zapcore.InfoLevel, Format: LogFormatJSON, Sampling: true}
+	if development {
+		config = LogConfig{Level: zapcore.DebugLevel, Format: LogFormatConsole, Sampling: false}
+	}
+
+	if value, found := os.LookupEnv(envLogLevel); found {
+		level, err := parseLogLevel(value)
+		if err != nil {
+			return LogConfig{}, fmt.Errorf("%w: %w", flagError(envLogLevel), err)
+		}
+		config.Level = level
+	}
+	if value, found := os.LookupEnv(envLogFormat); found {
+		config.Format = LogFormat(value)
+		if config.Format != LogFormatJSON && config.Format != LogFormatConsole {
+			return LogConfig{}, fmt.Errorf("%w: %w", flagError(envLogFormat), errInvalidLogFormat)
+		}
+	}
+	if value, found := os.LookupEnv(envLogSampling); found {
+		sampling, err := strconv.ParseBool(value)
+		if err != nil {
+			return LogConfig{}, fmt.Errorf("%w: %w", flagError(envLogSampling), err)
+		}
+		config.Sampling = sampling
+	}
+	return config, nil
+}
+
+// parseLogLevel accepts a zap level name or the verbosity of a logr logger.
+func parseLogLevel(value string) (zapcore.Level, error) {
+	verbosity, err := strconv.Atoi(value)
+	if err == nil {
+		if verbosity < 0 || verbosity > math.MaxInt8 {
+			return zapcore.InvalidLevel, errInvalidLogLevel
+		}
+		return zapcore.Level(-verbosity), nil
+	}
+	level, err := zapcore.ParseLevel(value)
+	if err != nil || level > zapcore.ErrorLevel {
+		return zapcore.InvalidLevel, errInvalidLogLevel
+	}
+	return level, nil
+}
@@ -0,0 +1,168 @@
+package serverconfig
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/fieldpath"
+)
+
+const (
+	envModuleConfig     = "MODULE_CONFIG"
+	defaultMaxPatchSize = 4096
+)
+
+// ChangeSummary selects how the changes of an UPDATE are described in the event.
+type ChangeSummary string
+
+const (
+	ChangeSummaryNone  ChangeSummary = ""
+	ChangeSummaryPaths ChangeSummary = "paths"
+	ChangeSummaryPatch ChangeSummary = "patch"
+)
+
+var (
+	errInvalidChangeSummary = errors.New("changeSummary must be empty, paths or patch")
+	errWatchedFieldWildcard = errors.New("watchedFields must not contain wildcards")
+)
+
+// ModuleConfig holds the settings for the events of a single module.
+// The module is identified by the name in the /validate/<module> path of the admission request.
+type ModuleConfig struct {
+	// DebounceWindow merges repeated events for the same object into one delivery per window.
+	// Zero disables debouncing.
+	DebounceWindow metav1.Duration `json:"debounceWindow,omitempty"`
+	// ChangeSummary adds the changed JSON paths or a JSON patch of the watched fields to UPDATE events.
+	ChangeSummary ChangeSummary `json:"changeSummary,omitempty"`
+	// MaxPatchSize is the maximum size of the JSON patch in bytes. A larger patch is replaced by
+	// the changed JSON paths. It defaults to 4096 bytes, as the KCP listener limits the request size.
+	MaxPatchSize int `json:"maxPatchSize,omitempty"`
+	// WatchedFields are selectors of the fields compared to detect a change in an UPDATE,
+	// e.g. .metadata.labels or .metadata.annotations["example.com/owner"]. If set, they replace
+	// the comparison of .spec or .status and are compared regardless of the subresource.
+	WatchedFields []string `json:"watchedFields,omitempty"`
+	// IgnoredFields are selectors of fields removed from both objects of an UPDATE before they are compared,
+	// e.g. .status.lastHeartbeatTime or .status.conditions[*].lastTransitionTime.
+	IgnoredFields []string `json:"ignoredFields,omitempty"`
+	// Destination routes the events of the module to another KCP listener than the global one.
+	Destination Destination `json:"destination,omitempty"`
+	// ForwardDryRun forwards dry-run admission requests with the DryRun flag set in the event.
+	// By default, they are not forwarded, as nothing was persisted.
+	ForwardDryRun bool `json:"forwardDryRun,omitempty"`
+	// UserFilter selects the changes that are forwarded by the user who made them.
+	UserFilter UserFilter `json:"userFilter,omitempty"`
+	// ShadowDestinations receive the events of the module in addition to the destination,
+	// e.g. a new KCP gateway during a migration.
+	ShadowDestinations []Destination `json:"shadowDestinations,omitempty"`
+	// RateLimit limits the events of the module and of each watched object forwarded to KCP.
+	RateLimit RateLimit `json:"rateLimit,omitempty"`
+
+	watchedFieldPaths []fieldpath.Path
+	ignoredFieldPaths []fieldpath.Path
+}
+
+// WatchedFieldPaths returns the parsed WatchedFields.
+func (m ModuleConfig) WatchedFieldPaths() []fieldpath.Path {
+	return m.watchedFieldPaths
+}
+
+// IgnoredFieldPaths returns the parsed IgnoredFields.
+func (m ModuleConfig) IgnoredFieldPaths() []fieldpath.Path {
+	return m.ignoredFieldPaths
+}
+
+// ModuleConfig returns the settings for the given module, or the defaults if none are configured.
+func (s *ServerConfig) ModuleConfig(moduleName string) ModuleConfig {
+	module, found := s.Modules[moduleName]
+	if !found {
+		return ModuleConfig{MaxPatchSize: defaultMaxPatchSize}
+	}
+	return module
+}
+
+// parseModuleConfigFile reads a YAML or JSON file mapping module names to their ModuleConfig, e.g.
+//
+//	kyma:
+//	  debounceWindow: 5s
+//	  changeSummary: patch
+//	  watchedFields:
+//	  - .spec
+//	  - .metadata.labels
+//	  ignoredFields:
+//	  - .status.conditions[*].lastTransitionTime
+//	  userFilter:
+//	    exclude:
+//	    - serviceAccount: kcp-system/lifecycle-manager
+//	  destination:
+//	    address: kcp-gateway.example.com
+//	    contract: v3
+//	  shadowDestinations:
+//	  - address: new-kcp-gateway.example.com
+//	    mode: best-effort
+//	  rateLimit:
+//	    module:
+//	      eventsPerSecond: 10
+//	    object:
+//	      eventsPerSecond: 0.2
+//	      burst: 3
+func parseModuleConfigFile(path string) (map[string]ModuleConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("could not read module config: %w", err)
+	}
+	modules := map[string]ModuleConfig{}
+	err = yaml.UnmarshalStrict(content, &modules)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse module config: %w", err)
+	}
+	for name, module := range modules {
+		switch module.ChangeSummary {
+		case ChangeSummaryNone, ChangeSummaryPaths, ChangeSummaryPatch:
+		default:
+			return nil, fmt.Errorf("invalid config of module %s: %w", name, errInvalidChangeSummary)
+		}
+		err = validateDestinations(module)
+		if err == nil {
+			err = module.UserFilter.validate()
+		}
+		if err == nil {
+			err = module.RateLimit.validate()
+		}
+		if err == nil {
+			module.watchedFieldPaths, err = parseFieldPaths(module.WatchedFields)
+		}
+		if err == nil {
+			module.ignoredFieldPaths, err = parseFieldPaths(module.IgnoredFields)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid config of module %s: %w", name, err)
+		}
+		for _, path := range module.watchedFieldPaths {
+			if path.HasWildcard() {
+				return nil, fmt.Errorf("invalid config of module %s: %w", name, errWatchedFieldWildcard)
+			}
+		}
+		if module.MaxPatchSize <= 0 {
+			module.MaxPatchSize = defaultMaxPatchSize
+		}
+		module.RateLimit = module.RateLimit.withDefaults()
+		modules[name] = module
+	}
+	return modules, nil
+}
+
+func parseFieldPaths(selectors []string) ([]fieldpath.Path, error) {
+	paths := make([]fieldpath.Path, 0, len(selectors))
+	for _, selector := range selectors {
+		path, err := fieldpath.Parse(selector)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse field path: %w", err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
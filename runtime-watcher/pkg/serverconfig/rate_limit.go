@@ -0,0 +1,61 @@
+package serverconfig
+
+import (
+	"errors"
+	"math"
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const defaultRateLimitMaxDelay = time.Minute
+
+var errInvalidRateLimit = errors.New("rate limit must not be negative")
+
+// RateLimit limits the events of a module forwarded to KCP with a token bucket for the module
+// and one for each watched object. Events exceeding a limit are delayed and coalesced per object,
+// or dropped if they would be delayed longer than MaxDelay.
+type RateLimit struct {
+	// Module limits all events of the module.
+	Module TokenBucket `json:"module,omitempty"`
+	// Object limits the events of each watched object.
+	Object TokenBucket `json:"object,omitempty"`
+	// MaxDelay is the longest time an event is delayed before it is dropped. It defaults to one minute.
+	MaxDelay metav1.Duration `json:"maxDelay,omitempty"`
+}
+
+// TokenBucket allows Burst events at once and EventsPerSecond events on average. Zero EventsPerSecond
+// disables the limit. Burst defaults to EventsPerSecond rounded up.
+type TokenBucket struct {
+	EventsPerSecond float64 `json:"eventsPerSecond,omitempty"`
+	Burst           int     `json:"burst,omitempty"`
+}
+
+// IsEmpty reports whether the events of the module are not limited.
+func (r RateLimit) IsEmpty() bool {
+	return r.Module.EventsPerSecond == 0 && r.Object.EventsPerSecond == 0
+}
+
+func (r RateLimit) validate() error {
+	if r.Module.EventsPerSecond < 0 || r.Module.Burst < 0 || r.Object.EventsPerSecond < 0 || r.Object.Burst < 0 ||
+		r.MaxDelay.Duration < 0 {
+		return errInvalidRateLimit
+	}
+	return nil
+}
+
+func (r RateLimit) withDefaults() RateLimit {
+	r.Module = r.Module.withDefaults()
+	r.Object = r.Object.withDefaults()
+	if r.MaxDelay.Duration == 0 {
+		r.MaxDelay.Duration = defaultRateLimitMaxDelay
+	}
+	return r
+}
+
+func (b TokenBucket) withDefaults() TokenBucket {
+	if b.Burst == 0 {
+		b.Burst = int(math.Ceil(b.EventsPerSecond))
+	}
+	return b
+}
@@ -0,0 +1,61 @@
+package serverconfig
+
+import (
+	"errors"
+	"slices"
+	"strings"
+)
+
+var (
+	errEmptyUserRule             = errors.New("user rule must set username, group or serviceAccount")
+	errInvalidServiceAccountRule = errors.New("serviceAccount must be written as <namespace>/<name>")
+)
+
+// UserFilter selects the changes of a module that are forwarded by the user who made them,
+// e.g. to not forward the changes KCP made itself.
+type UserFilter struct {
+	// Include forwards only changes by users matching one of the rules. If empty, changes by all users are included.
+	Include []UserRule `json:"include,omitempty"`
+	// Exclude does not forward changes by users matching one of the rules, even if they are included.
+	Exclude []UserRule `json:"exclude,omitempty"`
+}
+
+// UserRule matches the user of an admission request if all of its set fields match.
+type UserRule struct {
+	// Username matches the name of the user, e.g. kubernetes-admin.
+	Username string `json:"username,omitempty"`
+	// Group matches if the user is a member of the group, e.g. system:masters.
+	Group string `json:"group,omitempty"`
+	// ServiceAccount matches a service account written as <namespace>/<name>, e.g. kyma-system/lifecycle-manager.
+	ServiceAccount string `json:"serviceAccount,omitempty"`
+}
+
+// IsEmpty reports whether the filter forwards the changes by all users.
+func (f UserFilter) IsEmpty() bool {
+	return len(f.Include) == 0 && len(f.Exclude) == 0
+}
+
+// ServiceAccountUsername returns the username of the ServiceAccount,
+// e.g. system:serviceaccount:kyma-system:lifecycle-manager.
+func (r UserRule) ServiceAccountUsername() string {
+	if r.ServiceAccount == "" {
+		return ""
+	}
+	return "system:serviceaccount:" + strings.Replace(r.ServiceAccount, "/", ":", 1)
+}
+
+func (f UserFilter) validate() error {
+	for _, rule := range slices.Concat(f.Include, f.Exclude) {
+		if rule.Username == "" && rule.Group == "" && rule.ServiceAccount == "" {
+			return errEmptyUserRule
+		}
+		if rule.ServiceAccount == "" {
+			continue
+		}
+		namespace, name, found := strings.Cut(rule.ServiceAccount, "/")
+		if !found || namespace == "" || name == "" || strings.Contains(name, "/") {
+			return errInvalidServiceAccountRule
+		}
+	}
+	return nil
+}
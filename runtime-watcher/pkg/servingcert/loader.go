@@ -0,0 +1,93 @@
+package servingcert
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/go-logr/logr"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/certwatcher"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+var errMissingLeaf = errors.New("tls certificate has no leaf")
+
+type Config struct {
+	// Certificate labels the metrics of the loaded certificate.
+	Certificate watchermetrics.Certificate
+	TLSCertPath string
+	TLSKeyPath  string
+	// ReloadInterval is the time between two checks of the certificate files for changes.
+	ReloadInterval time.Duration
+}
+
+// Loader serves the certificate of a server, e.g. the webhook, through tls.Config.GetCertificate and swaps it
+// atomically when the mounted files change, so a rotated certificate is used without a restart.
+type Loader struct {
+	logger      logr.Logger
+	metrics     *watchermetrics.WatcherMetrics
+	watcher     *certwatcher.Watcher
+	certificate atomic.Pointer[tls.Certificate]
+}
+
+// New loads the certificate and returns a Loader serving it.
+func New(logger logr.Logger, config Config, metrics *watchermetrics.WatcherMetrics) (*Loader, error) {
+	loader := &Loader{
+		logger:  logger,
+		metrics: metrics,
+	}
+	loader.watcher = certwatcher.New(logger, certwatcher.Config{
+		Certificate: config.Certificate,
+		Paths:       []string{config.TLSCertPath, config.TLSKeyPath},
+		Interval:    config.ReloadInterval,
+	}, func() error {
+		return loader.load(config)
+	}, metrics)
+
+	err := loader.watcher.Load()
+	if err != nil {
+		return nil, err
+	}
+	return loader, nil
+}
+
+// GetCertificate returns the currently loaded certificate for every TLS handshake.
+func (l *Loader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	return l.certificate.Load(), nil
+}
+
+// Check returns an error if the certificate files could not be loaded on the last change
+// or the served certificate is not valid.
+func (l *Loader) Check() error {
+	err := l.watcher.Err()
+	if err != nil {
+		return err
+	}
+	return certwatcher.CheckValidity(l.certificate.Load().Leaf, time.Now())
+}
+
+// Run reloads the certificate when the files change until ctx is done.
+func (l *Loader) Run(ctx context.Context) {
+	l.watcher.Run(ctx)
+}
+
+func (l *Loader) load(config Config) error {
+	certificate, err := tls.LoadX509KeyPair(config.TLSCertPath, config.TLSKeyPath)
+	if err != nil {
+		return fmt.Errorf("could not load tls certificate :%w", err)
+	}
+	if certificate.Leaf == nil {
+		return errMissingLeaf
+	}
+	l.certificate.Store(&certificate)
+
+	l.metrics.UpdateLoadedCertificate(config.Certificate,
+		certificate.Leaf.SerialNumber.String(), certificate.Leaf.NotAfter)
+	l.logger.Info("loaded serving certificate", "certificate", config.Certificate,
+		"serialNumber", certificate.Leaf.SerialNumber.String(), "notAfter", certificate.Leaf.NotAfter)
+	return nil
+}
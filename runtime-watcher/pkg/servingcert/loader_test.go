@@ -0,0 +1,78 @@
+package servingcert_test
+
+import (
+	"crypto/tls"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/kyma-project/runtime-watcher/skr/pkg/servingcert"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/tlstest"
+	"github.com/kyma-project/runtime-watcher/skr/pkg/watchermetrics"
+)
+
+func servedSerialNumber(t *testing.T, loader *servingcert.Loader) string {
+	t.Helper()
+	certificate, err := loader.GetCertificate(&tls.ClientHelloInfo{})
+	require.NoError(t, err)
+	return certificate.Leaf.SerialNumber.String()
+}
+
+func TestLoader_SwapsCertificateWhenFilesChange(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	loader, err := servingcert.New(logr.Discard(), servingcert.Config{
+		TLSCertPath:    certProvider.ClientCertFile.Name(),
+		TLSKeyPath:     certProvider.ClientKeyFile.Name(),
+		ReloadInterval: 10 * time.Millisecond,
+	}, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	go loader.Run(t.Context())
+	initial := servedSerialNumber(t, loader)
+
+	require.NoError(t, certProvider.GenerateCerts())
+
+	assert.Eventually(t, func() bool {
+		return servedSerialNumber(t, loader) != initial
+	}, time.Second, 10*time.Millisecond)
+}
+
+func TestLoader_KeepsCertificateWhenReloadFails(t *testing.T) {
+	t.Parallel()
+	certProvider, err := tlstest.NewCertProvider()
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = certProvider.CleanUp() })
+	loader, err := servingcert.New(logr.Discard(), servingcert.Config{
+		TLSCertPath:    certProvider.ClientCertFile.Name(),
+		TLSKeyPath:     certProvider.ClientKeyFile.Name(),
+		ReloadInterval: 10 * time.Millisecond,
+	}, watchermetrics.NewMetrics())
+	require.NoError(t, err)
+	go loader.Run(t.Context())
+	initial := servedSerialNumber(t, loader)
+	require.NoError(t, loader.Check())
+
+	require.NoError(t, os.WriteFile(certProvider.ClientCertFile.Name(), []byte("invalid"), 0o600))
+	time.Sleep(50 * time.Millisecond)
+
+	assert.Equal(t, initial, servedSerialNumber(t, loader))
+	assert.Error(t, loader.Check())
+}
+
+func TestNew_ReturnsErrorForMissingCertificate(t *testing.T) {
+	t.Parallel()
+	_, err := servingcert.New(logr.Discard(), servingcert.Config{
+		TLSCertPath:    filepath.Join(t.TempDir(), "tls.crt"),
+		TLSKeyPath:     filepath.Join(t.TempDir(), "tls.key"),
+		ReloadInterval: time.Second,
+	}, watchermetrics.NewMetrics())
+
+	require.Error(t, err)
+}
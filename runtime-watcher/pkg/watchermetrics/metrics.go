@@ -14,6 +14,26 @@ const (
 	FipsModeOnly = 2
 )
 
+type BreakerState int
+
+const (
+	BreakerClosed   BreakerState = 0
+	BreakerOpen     BreakerState = 1
+	BreakerHalfOpen BreakerState = 2
+)
+
+func (s BreakerState) String() string {
+	switch s {
+	case BreakerClosed:
+		return "closed"
+	case BreakerOpen:
+		return "open"
+	case BreakerHalfOpen:
+		return "half-open"
+	}
+	return "unknown"
+}
+
 type WatcherMetrics struct {
 	requestDurationGauge               prometheus.Gauge
 	fipsModeGauge                      prometheus.Gauge
@@ -21,23 +41,101 @@ type WatcherMetrics struct {
 	admissionRequestsTotalCounter      prometheus.Counter
 	kcpRequestsTotalCounter            prometheus.Counter
 	failedKCPRequestsTotalCounter      *prometheus.CounterVec
+	queueDepthGauge                    prometheus.Gauge
+	queueDroppedEventsTotalCounter     *prometheus.CounterVec
+	queueRetriesTotalCounter           prometheus.Counter
+	coalescedEventsTotalCounter        *prometheus.CounterVec
+	spoolDepthGauge                    prometheus.Gauge
+	spoolOldestEventAgeGauge           prometheus.Gauge
+	spoolDroppedEventsTotalCounter     *prometheus.CounterVec
+	breakerStateGauge                  *prometheus.GaugeVec
+	breakerTransitionsTotalCounter     *prometheus.CounterVec
+	breakerRejectedTotalCounter        *prometheus.CounterVec
+	certificateReloadsTotalCounter     *prometheus.CounterVec
+	certificateExpiryGauge             *prometheus.GaugeVec
+	certificateInfoGauge               *prometheus.GaugeVec
+	suppressedEventsTotalCounter       *prometheus.CounterVec
+	destinationDeliveriesTotalCounter  *prometheus.CounterVec
+	dryRunSkippedTotalCounter          *prometheus.CounterVec
+	userFilterTotalCounter             *prometheus.CounterVec
+	throttledEventsTotalCounter        *prometheus.CounterVec
+	shutdownsTotalCounter              *prometheus.CounterVec
 }
 
 const (
-	RequestDuration                          = "watcher_request_duration"
-	WatcherFipsMode                          = "watcher_fips_mode"
-	FailedKCPRequestsTotal                   = "watcher_failed_kcp_total"
-	KcpRequestsTotal                         = "watcher_kcp_requests_total"
-	AdmissionRequestsErrorTotal              = "watcher_admission_request_error_total"
-	AdmissionRequestsTotal                   = "watcher_admission_request_total"
-	kcpErrReasonLabel                        = "error_reason"
-	ReasonSubresource           KcpErrReason = "invalid-subresource"
-	ReasonKcpAddress            KcpErrReason = "missing-address-or-contract"
-	ReasonRequest               KcpErrReason = "request-setup"
-	ReasonResponse              KcpErrReason = "failed-request"
+	RequestDuration                                  = "watcher_request_duration"
+	WatcherFipsMode                                  = "watcher_fips_mode"
+	FailedKCPRequestsTotal                           = "watcher_failed_kcp_total"
+	KcpRequestsTotal                                 = "watcher_kcp_requests_total"
+	AdmissionRequestsErrorTotal                      = "watcher_admission_request_error_total"
+	AdmissionRequestsTotal                           = "watcher_admission_request_total"
+	QueueDepth                                       = "watcher_queue_depth"
+	QueueDroppedEventsTotal                          = "watcher_queue_dropped_events_total"
+	QueueRetriesTotal                                = "watcher_queue_retries_total"
+	CoalescedEventsTotal                             = "watcher_coalesced_events_total"
+	SpoolDepth                                       = "watcher_spool_depth"
+	SpoolOldestEventAge                              = "watcher_spool_oldest_event_age_seconds"
+	SpoolDroppedEventsTotal                          = "watcher_spool_dropped_events_total"
+	CircuitBreakerState                              = "watcher_circuit_breaker_state"
+	CircuitBreakerTransitionsTotal                   = "watcher_circuit_breaker_transitions_total"
+	CircuitBreakerRejectedTotal                      = "watcher_circuit_breaker_rejected_total"
+	CertificateReloadsTotal                          = "watcher_certificate_reloads_total"
+	CertificateExpiry                                = "watcher_certificate_expiry_timestamp_seconds"
+	CertificateInfo                                  = "watcher_certificate_info"
+	SuppressedEventsTotal                            = "watcher_suppressed_events_total"
+	DestinationDeliveriesTotal                       = "watcher_destination_deliveries_total"
+	DryRunSkippedTotal                               = "watcher_dry_run_skipped_total"
+	UserFilterTotal                                  = "watcher_user_filter_total"
+	ThrottledEventsTotal                             = "watcher_throttled_events_total"
+	ShutdownsTotal                                   = "watcher_shutdowns_total"
+	kcpErrReasonLabel                                = "error_reason"
+	dropReasonLabel                                  = "drop_reason"
+	moduleLabel                                      = "module"
+	destinationLabel                                 = "destination"
+	fromStateLabel                                   = "from"
+	toStateLabel                                     = "to"
+	certificateLabel                                 = "certificate"
+	resultLabel                                      = "result"
+	serialNumberLabel                                = "serial_number"
+	modeLabel                                        = "mode"
+	outcomeLabel                                     = "outcome"
+	actionLabel                                      = "action"
+	ReasonSubresource              KcpErrReason      = "invalid-subresource"
+	ReasonKcpAddress               KcpErrReason      = "missing-address-or-contract"
+	ReasonRequest                  KcpErrReason      = "request-setup"
+	ReasonResponse                 KcpErrReason      = "failed-request"
+	ReasonRejected                 KcpErrReason      = "rejected-event"
+	DropReasonQueueFull            DropReason        = "queue-full"
+	DropReasonShutdown             DropReason        = "shutdown"
+	DropReasonRetriesExhausted     DropReason        = "retries-exhausted"
+	DropReasonSpoolFull            DropReason        = "spool-full"
+	DropReasonExpired              DropReason        = "expired"
+	CertificateKCPClient           Certificate       = "kcp-client"
+	CertificateWebhookServing      Certificate       = "webhook-serving"
+	CertificateMetricsServing      Certificate       = "metrics-serving"
+	ReloadSucceeded                Result            = "success"
+	ReloadFailed                   Result            = "failure"
+	DeliverySucceeded              Result            = "success"
+	DeliveryFailed                 Result            = "failure"
+	DeliverySkipped                Result            = "skipped"
+	ShutdownSucceeded              Result            = "success"
+	ShutdownFailed                 Result            = "failure"
+	UserForwarded                  UserFilterOutcome = "forwarded"
+	UserExcluded                   UserFilterOutcome = "excluded"
+	UserNotIncluded                UserFilterOutcome = "not-included"
+	ThrottleDelayed                ThrottleAction    = "delayed"
+	ThrottleDropped                ThrottleAction    = "dropped"
+	ThrottleReplaced               ThrottleAction    = "replaced"
 )
 
-type KcpErrReason string
+type (
+	KcpErrReason      string
+	DropReason        string
+	Certificate       string
+	Result            string
+	UserFilterOutcome string
+	ThrottleAction    string
+)
 
 func NewMetrics() *WatcherMetrics {
 	metrics := &WatcherMetrics{
@@ -65,6 +163,82 @@ func NewMetrics() *WatcherMetrics {
 			Name: WatcherFipsMode,
 			Help: "current FIPS mode (0=off/1=on/2=only)",
 		}),
+		queueDepthGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: QueueDepth,
+			Help: "Indicates the number of events waiting in the forwarding queue",
+		}),
+		queueDroppedEventsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: QueueDroppedEventsTotal,
+			Help: "Indicates total events dropped by the forwarding queue",
+		}, []string{dropReasonLabel}),
+		queueRetriesTotalCounter: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: QueueRetriesTotal,
+			Help: "Indicates total delivery retries of the forwarding queue",
+		}),
+		coalescedEventsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: CoalescedEventsTotal,
+			Help: "Indicates total events merged into a pending event for the same object",
+		}, []string{moduleLabel}),
+		spoolDepthGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: SpoolDepth,
+			Help: "Indicates the number of undelivered events in the spool",
+		}),
+		spoolOldestEventAgeGauge: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: SpoolOldestEventAge,
+			Help: "Indicates the age of the oldest event in the spool in seconds",
+		}),
+		spoolDroppedEventsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: SpoolDroppedEventsTotal,
+			Help: "Indicates total events dropped from the spool",
+		}, []string{dropReasonLabel}),
+		breakerStateGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: CircuitBreakerState,
+			Help: "current circuit breaker state (0=closed/1=open/2=half-open)",
+		}, []string{destinationLabel}),
+		breakerTransitionsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: CircuitBreakerTransitionsTotal,
+			Help: "Indicates total circuit breaker state transitions",
+		}, []string{destinationLabel, fromStateLabel, toStateLabel}),
+		breakerRejectedTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: CircuitBreakerRejectedTotal,
+			Help: "Indicates total requests rejected by an open circuit breaker",
+		}, []string{destinationLabel}),
+		certificateReloadsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: CertificateReloadsTotal,
+			Help: "Indicates total loads of a certificate from the mounted files",
+		}, []string{certificateLabel, resultLabel}),
+		certificateExpiryGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: CertificateExpiry,
+			Help: "Indicates the NotAfter time of the currently loaded certificate as unix timestamp",
+		}, []string{certificateLabel}),
+		certificateInfoGauge: prometheus.NewGaugeVec(prometheus.GaugeOpts{
+			Name: CertificateInfo,
+			Help: "Identifies the currently loaded certificate, the value is always 1",
+		}, []string{certificateLabel, serialNumberLabel}),
+		suppressedEventsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: SuppressedEventsTotal,
+			Help: "Indicates total UPDATE events not forwarded because only ignored fields changed",
+		}, []string{moduleLabel}),
+		destinationDeliveriesTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: DestinationDeliveriesTotal,
+			Help: "Indicates total deliveries of events to a KCP destination",
+		}, []string{destinationLabel, modeLabel, resultLabel}),
+		dryRunSkippedTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: DryRunSkippedTotal,
+			Help: "Indicates total dry-run admission requests not forwarded to KCP",
+		}, []string{moduleLabel}),
+		userFilterTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: UserFilterTotal,
+			Help: "Indicates total admission requests checked against the user filter of a module by outcome",
+		}, []string{moduleLabel, outcomeLabel}),
+		throttledEventsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: ThrottledEventsTotal,
+			Help: "Indicates total events delayed, dropped or replaced by the rate limit of a module",
+		}, []string{moduleLabel, actionLabel}),
+		shutdownsTotalCounter: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: ShutdownsTotal,
+			Help: "Indicates total graceful shutdowns by whether pending events were delivered within the grace period",
+		}, []string{resultLabel}),
 	}
 	return metrics
 }
@@ -76,6 +250,25 @@ func (w *WatcherMetrics) RegisterAll() {
 	prometheus.MustRegister(w.admissionRequestsTotalCounter)
 	prometheus.MustRegister(w.kcpRequestsTotalCounter)
 	prometheus.MustRegister(w.failedKCPRequestsTotalCounter)
+	prometheus.MustRegister(w.queueDepthGauge)
+	prometheus.MustRegister(w.queueDroppedEventsTotalCounter)
+	prometheus.MustRegister(w.queueRetriesTotalCounter)
+	prometheus.MustRegister(w.coalescedEventsTotalCounter)
+	prometheus.MustRegister(w.spoolDepthGauge)
+	prometheus.MustRegister(w.spoolOldestEventAgeGauge)
+	prometheus.MustRegister(w.spoolDroppedEventsTotalCounter)
+	prometheus.MustRegister(w.breakerStateGauge)
+	prometheus.MustRegister(w.breakerTransitionsTotalCounter)
+	prometheus.MustRegister(w.breakerRejectedTotalCounter)
+	prometheus.MustRegister(w.certificateReloadsTotalCounter)
+	prometheus.MustRegister(w.certificateExpiryGauge)
+	prometheus.MustRegister(w.certificateInfoGauge)
+	prometheus.MustRegister(w.suppressedEventsTotalCounter)
+	prometheus.MustRegister(w.destinationDeliveriesTotalCounter)
+	prometheus.MustRegister(w.dryRunSkippedTotalCounter)
+	prometheus.MustRegister(w.userFilterTotalCounter)
+	prometheus.MustRegister(w.throttledEventsTotalCounter)
+	prometheus.MustRegister(w.shutdownsTotalCounter)
 }
 
 func (w *WatcherMetrics) UpdateRequestDuration(duration time.Duration) {
@@ -111,3 +304,115 @@ func (w *WatcherMetrics) UpdateAdmissionRequestsErrorTotal() {
 func (w *WatcherMetrics) UpdateAdmissionRequestsTotal() {
 	w.admissionRequestsTotalCounter.Inc()
 }
+
+func (w *WatcherMetrics) UpdateQueueDepth(depth int) {
+	w.queueDepthGauge.Set(float64(depth))
+}
+
+func (w *WatcherMetrics) UpdateQueueDroppedEventsTotal(reason DropReason) {
+	w.queueDroppedEventsTotalCounter.With(prometheus.Labels{
+		dropReasonLabel: string(reason),
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateQueueRetriesTotal() {
+	w.queueRetriesTotalCounter.Inc()
+}
+
+func (w *WatcherMetrics) UpdateCoalescedEventsTotal(moduleName string) {
+	w.coalescedEventsTotalCounter.With(prometheus.Labels{
+		moduleLabel: moduleName,
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateSpool(depth int, oldestEventAge time.Duration) {
+	w.spoolDepthGauge.Set(float64(depth))
+	w.spoolOldestEventAgeGauge.Set(oldestEventAge.Seconds())
+}
+
+func (w *WatcherMetrics) UpdateSpoolDroppedEventsTotal(reason DropReason) {
+	w.spoolDroppedEventsTotalCounter.With(prometheus.Labels{
+		dropReasonLabel: string(reason),
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateBreakerState(destination string, state BreakerState) {
+	w.breakerStateGauge.With(prometheus.Labels{
+		destinationLabel: destination,
+	}).Set(float64(state))
+}
+
+func (w *WatcherMetrics) UpdateBreakerTransitionsTotal(destination string, from, to BreakerState) {
+	w.breakerTransitionsTotalCounter.With(prometheus.Labels{
+		destinationLabel: destination,
+		fromStateLabel:   from.String(),
+		toStateLabel:     to.String(),
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateBreakerRejectedTotal(destination string) {
+	w.breakerRejectedTotalCounter.With(prometheus.Labels{
+		destinationLabel: destination,
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateCertificateReloadsTotal(certificate Certificate, result Result) {
+	w.certificateReloadsTotalCounter.With(prometheus.Labels{
+		certificateLabel: string(certificate),
+		resultLabel:      string(result),
+	}).Inc()
+}
+
+// UpdateLoadedCertificate replaces the expiry and identity of the given certificate.
+func (w *WatcherMetrics) UpdateLoadedCertificate(certificate Certificate, serialNumber string, notAfter time.Time) {
+	w.certificateExpiryGauge.With(prometheus.Labels{
+		certificateLabel: string(certificate),
+	}).Set(float64(notAfter.Unix()))
+	w.certificateInfoGauge.DeletePartialMatch(prometheus.Labels{
+		certificateLabel: string(certificate),
+	})
+	w.certificateInfoGauge.With(prometheus.Labels{
+		certificateLabel:  string(certificate),
+		serialNumberLabel: serialNumber,
+	}).Set(1)
+}
+
+func (w *WatcherMetrics) UpdateSuppressedEventsTotal(moduleName string) {
+	w.suppressedEventsTotalCounter.With(prometheus.Labels{
+		moduleLabel: moduleName,
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateDestinationDeliveriesTotal(destination, mode string, result Result) {
+	w.destinationDeliveriesTotalCounter.With(prometheus.Labels{
+		destinationLabel: destination,
+		modeLabel:        mode,
+		resultLabel:      string(result),
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateDryRunSkippedTotal(moduleName string) {
+	w.dryRunSkippedTotalCounter.With(prometheus.Labels{
+		moduleLabel: moduleName,
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateUserFilterTotal(moduleName string, outcome UserFilterOutcome) {
+	w.userFilterTotalCounter.With(prometheus.Labels{
+		moduleLabel:  moduleName,
+		outcomeLabel: string(outcome),
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateThrottledEventsTotal(moduleName string, action ThrottleAction) {
+	w.throttledEventsTotalCounter.With(prometheus.Labels{
+		moduleLabel: moduleName,
+		actionLabel: string(action),
+	}).Inc()
+}
+
+func (w *WatcherMetrics) UpdateShutdownsTotal(result Result) {
+	w.shutdownsTotalCounter.With(prometheus.Labels{
+		resultLabel: string(result),
+	}).Inc()
+}
@@ -81,6 +81,8 @@ var _ = BeforeSuite(func() {
 	_ = os.Setenv("CA_CERT", certProvider.RootCertFile.Name())
 	_ = os.Setenv("TLS_CERT", certProvider.ClientCertFile.Name())
 	_ = os.Setenv("TLS_KEY", certProvider.ClientKeyFile.Name())
+	// deliver within the admission request, so the KCP payload can be checked right after Handle
+	_ = os.Setenv("FORWARDING_WORKERS", "0")
 })
 
 var _ = AfterSuite(func() {
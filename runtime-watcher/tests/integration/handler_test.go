@@ -109,7 +109,8 @@ var _ = Describe("given watched resource", Ordered, func() {
 		decoder := serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
 		requestParser := requestparser.NewRequestParser(decoder)
 		metrics := watchermetrics.NewMetrics()
-		handler := admissionreview.NewHandler(logger, config, *requestParser, *metrics)
+		handler, err := admissionreview.NewHandler(logger, config, *requestParser, *metrics)
+		Expect(err).ShouldNot(HaveOccurred())
 		skrRecorder := httptest.NewRecorder()
 		handler.Handle(skrRecorder, request)
 
@@ -146,8 +147,50 @@ var _ = Describe("given watched resource", Ordered, func() {
 					},
 					WatchedGvk: metav1.GroupVersionKind(schema.FromAPIVersionAndKind(WatchedResourceAPIVersion,
 						WatchedResourceKind)),
+					Operation: listenerTypes.Operation(testCase.params.operation),
 				},
 			))
 		}
 	}, createTableEntries())
 })
+
+var _ = Describe("given asynchronous forwarding", Ordered, func() {
+	BeforeEach(func() {
+		kcpRecorder.Flush()
+	})
+	It("should queue admission request and send payload to KCP in the background", func(ctx SpecContext) {
+		logger := ctrl.Log.WithName("skr-watcher-test")
+		config, err := serverconfig.ParseFromEnv(logger)
+		Expect(err).ShouldNot(HaveOccurred())
+		config.ForwardingWorkers = 1
+
+		managedByLabel := map[string]string{"operator.kyma-project.io/managed-by": "lifecycle-manager"}
+		namespacedName := fmt.Sprintf("%s/%s", metav1.NamespaceDefault, ownerName)
+		ownedByAnnotation := map[string]string{"operator.kyma-project.io/owned-by": namespacedName}
+		request, err := GetAdmissionHTTPRequest(admissionv1.Create, crName, moduleName, managedByLabel,
+			ownedByAnnotation, SpecChange)
+		Expect(err).ShouldNot(HaveOccurred())
+
+		decoder := serializer.NewCodecFactory(runtime.NewScheme()).UniversalDeserializer()
+		requestParser := requestparser.NewRequestParser(decoder)
+		handler, err := admissionreview.NewHandler(logger, config, *requestParser, *watchermetrics.NewMetrics())
+		Expect(err).ShouldNot(HaveOccurred())
+		handler.Start()
+		skrRecorder := httptest.NewRecorder()
+		handler.Handle(skrRecorder, request)
+
+		admissionReview := admissionv1.AdmissionReview{}
+		_, _, err = decoder.Decode(skrRecorder.Body.Bytes(), nil, &admissionReview)
+		Expect(err).ShouldNot(HaveOccurred())
+		Expect(admissionReview.Response.Allowed).To(BeTrue())
+		Expect(admissionReview.Response.Result.Message).To(Equal("kcp request queued"))
+		Expect(admissionReview.Response.AuditAnnotations).To(HaveKeyWithValue("outcome", "queued"))
+
+		// the queue is drained on shutdown
+		Expect(handler.Shutdown(ctx)).To(Succeed())
+		watcherEvt := &listenerTypes.WatchEvent{}
+		Expect(json.Unmarshal(kcpRecorder.Body.Bytes(), watcherEvt)).To(Succeed())
+		Expect(watcherEvt.Watched).To(Equal(listenerTypes.ObjectKey{Name: crName, Namespace: metav1.NamespaceDefault}))
+		Expect(watcherEvt.Operation).To(Equal(listenerTypes.Operation(admissionv1.Create)))
+	})
+})
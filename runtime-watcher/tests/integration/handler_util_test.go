@@ -105,8 +105,9 @@ func GetAdmissionHTTPRequest(operation admissionv1.Operation, watchedName, modul
 	if err != nil {
 		return nil, err
 	}
-	return httptest.NewRequest(http.MethodGet, "/validate/"+moduleName,
-		bytes.NewBuffer(bytesRequest)), nil
+	request := httptest.NewRequest(http.MethodPost, "/validate/"+moduleName, bytes.NewBuffer(bytesRequest))
+	request.Header.Set("Content-Type", "application/json")
+	return request, nil
 }
 
 func createAdmissionRequest(operation admissionv1.Operation, watchedName string,